@@ -0,0 +1,243 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of an asynchronous run
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+	RunStatusCanceled  RunStatus = "canceled"
+)
+
+// ErrRunInProgress is returned when starting a run with the ID of another one that's still running
+var ErrRunInProgress = errors.New("run in progress")
+
+type (
+	// RunStatus of an asynchronous run, see RunHandle.Status
+	RunStatus string
+
+	// PanicError is the error an asynchronous run fails with when its step panics
+	PanicError struct {
+		// Value the step panicked with
+		Value any
+		// Stack of the goroutine when it panicked
+		Stack []byte
+	}
+
+	// RunHandle tracks a step run asynchronously (see RunAsync), letting us poll its status, cancel it or
+	// wait for its output. It's safe for concurrent use.
+	RunHandle[O any] struct {
+		cancel context.CancelFunc
+		done   chan struct{}
+
+		mux      sync.Mutex
+		status   RunStatus
+		started  time.Time
+		finished time.Time
+		out      O
+		err      error
+	}
+
+	// RunManager tracks the runs it starts by ID, so they can be looked up (eg. for polling them from a different
+	// request than the one that started them) or canceled.
+	//
+	// Finished runs are kept for the retention duration, after which they are forgotten. It's safe for concurrent use.
+	//
+	//   runs := pipeline.NewRunManager[Order, Receipt](time.Hour)
+	//   _, err := runs.Start(context.WithoutCancel(r.Context()), orderID, checkout, order)
+	//   // later on
+	//   run, ok := runs.Get(orderID)
+	RunManager[I, O any] struct {
+		retention time.Duration
+
+		mux  sync.Mutex
+		runs map[string]*RunHandle[O]
+	}
+)
+
+// RunAsync runs the step in a new goroutine, returning a handle for it.
+//
+// The run is canceled along with the context, so for runs outliving the caller (eg. the HTTP request
+// that triggered them) detach it first with context.WithoutCancel.
+//
+// Unlike the steps themselves, the run recovers from panics, as callers can't recover them from its goroutine.
+// A run whose step panicked fails with a PanicError. Panics raised in the goroutines the step spawns (eg. the
+// branches of a ConcurrentStep) can't be recovered though.
+func RunAsync[I, O any](ctx context.Context, step Step[I, O], in I) *RunHandle[O] {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &RunHandle[O]{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		status:  RunStatusRunning,
+		started: time.Now(),
+	}
+
+	go func() {
+		var out O
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+			h.finish(out, err, ctx.Err())
+			cancel()
+		}()
+
+		out, err = step.Run(ctx, in)
+	}()
+	return h
+}
+
+// NewRunManager creates a manager that keeps finished runs for the given retention
+func NewRunManager[I, O any](retention time.Duration) *RunManager[I, O] {
+	return &RunManager[I, O]{
+		retention: retention,
+		runs:      make(map[string]*RunHandle[O]),
+	}
+}
+
+// Status of the run. It's running until the step returns, and canceled if it failed after being canceled.
+func (h *RunHandle[O]) Status() RunStatus {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return h.status
+}
+
+// Started returns when the run started
+func (h *RunHandle[O]) Started() time.Time {
+	return h.started
+}
+
+// Finished returns when the run finished, or the zero time if it's still running
+func (h *RunHandle[O]) Finished() time.Time {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return h.finished
+}
+
+// Cancel the run by canceling its context. Steps are context aware, so it's up to them to stop once it's done.
+// Canceling a finished run is a no-op.
+func (h *RunHandle[O]) Cancel() {
+	h.cancel()
+}
+
+// Done returns a channel that's closed once the run finished
+func (h *RunHandle[O]) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait for the run to finish, returning its output and error. If the context is done first, its error
+// is returned instead (the run isn't canceled).
+func (h *RunHandle[O]) Wait(ctx context.Context) (O, error) {
+	select {
+	case <-h.done:
+		h.mux.Lock()
+		defer h.mux.Unlock()
+		return h.out, h.err
+	case <-ctx.Done():
+		return *new(O), ctx.Err()
+	}
+}
+
+func (h *RunHandle[O]) finish(out O, err, ctxErr error) {
+	var pe *PanicError
+
+	h.mux.Lock()
+	h.out = out
+	h.err = err
+	h.finished = time.Now()
+	switch {
+	case err == nil:
+		h.status = RunStatusSucceeded
+	case errors.As(err, &pe):
+		h.status = RunStatusFailed
+	case errors.Is(ctxErr, context.Canceled):
+		h.status = RunStatusCanceled
+	default:
+		h.status = RunStatusFailed
+	}
+	h.mux.Unlock()
+	close(h.done)
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("step panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it's an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Start running the step asynchronously under the given ID, which the run's context carries (see WithRunID)
+// so checkpoint steps resume from previous runs with the same ID.
+//
+// Starting a run replaces the finished one with the same ID (if any). If it's still running, ErrRunInProgress
+// is returned.
+func (m *RunManager[I, O]) Start(ctx context.Context, id string, step Step[I, O], in I) (*RunHandle[O], error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if prev, ok := m.runs[id]; ok && prev.Status() == RunStatusRunning {
+		return nil, fmt.Errorf("run %s can't be started: %w", id, ErrRunInProgress)
+	}
+
+	h := RunAsync(WithRunID(ctx, id), step, in)
+	m.runs[id] = h
+	go m.expire(id, h)
+	return h, nil
+}
+
+// Get the run with the given ID, or false if there's none (or it finished longer than the retention ago)
+func (m *RunManager[I, O]) Get(id string) (*RunHandle[O], bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	h, ok := m.runs[id]
+	return h, ok
+}
+
+// Cancel the run with the given ID, returning false if there's none
+func (m *RunManager[I, O]) Cancel(id string) bool {
+	h, ok := m.Get(id)
+	if ok {
+		h.Cancel()
+	}
+	return ok
+}
+
+// Active returns the sorted IDs of the runs still running
+func (m *RunManager[I, O]) Active() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var ids []string
+	for id, h := range m.runs {
+		if h.Status() == RunStatusRunning {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// expire forgets the run once it finished and the retention elapsed, unless it was already replaced
+func (m *RunManager[I, O]) expire(id string, h *RunHandle[O]) {
+	<-h.Done()
+	time.AfterFunc(m.retention, func() {
+		m.mux.Lock()
+		defer m.mux.Unlock()
+		if m.runs[id] == h {
+			delete(m.runs, id)
+		}
+	})
+}
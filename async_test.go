@@ -0,0 +1,164 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func newBlockingUnit(release <-chan struct{}) pipeline.Step[int, int] {
+	return pipeline.NewUnitStep("blocking", func(ctx context.Context, i int) (int, error) {
+		select {
+		case <-release:
+			return i + 1, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	})
+}
+
+func TestRunAsync_GivenARunningStep_WhenItFinishes_ThenItSucceeds(t *testing.T) {
+	release := make(chan struct{})
+	h := pipeline.RunAsync(context.Background(), newBlockingUnit(release), 1)
+
+	assert.Equal(t, pipeline.RunStatusRunning, h.Status())
+	assert.True(t, h.Finished().IsZero())
+	close(release)
+	res, err := h.Wait(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res)
+	assert.Equal(t, pipeline.RunStatusSucceeded, h.Status())
+	assert.False(t, h.Finished().Before(h.Started()))
+	<-h.Done()
+}
+
+func TestRunAsync_GivenAFailingStep_WhenWaited_ThenItFails(t *testing.T) {
+	expectedErr := errors.New("some error")
+	h := pipeline.RunAsync[int, int](context.Background(), pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return 0, expectedErr
+	}), 1)
+
+	_, err := h.Wait(context.Background())
+
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, pipeline.RunStatusFailed, h.Status())
+}
+
+func TestRunAsync_GivenARunningStep_WhenCanceled_ThenItIsCanceled(t *testing.T) {
+	h := pipeline.RunAsync(context.Background(), newBlockingUnit(nil), 1)
+
+	h.Cancel()
+	_, err := h.Wait(context.Background())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, pipeline.RunStatusCanceled, h.Status())
+}
+
+func TestRunAsync_GivenADoneWaitContext_WhenWaited_ThenTheRunKeepsGoing(t *testing.T) {
+	release := make(chan struct{})
+	h := pipeline.RunAsync(context.Background(), newBlockingUnit(release), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := h.Wait(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, pipeline.RunStatusRunning, h.Status())
+	close(release)
+	res, err := h.Wait(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res)
+}
+
+func TestRunAsync_GivenAPanickingStep_WhenWaited_ThenItFailsWithThePanic(t *testing.T) {
+	expectedErr := errors.New("some error")
+	h := pipeline.RunAsync[int, int](context.Background(), pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		panic(expectedErr)
+	}), 1)
+
+	<-h.Done()
+	_, err := h.Wait(context.Background())
+
+	var pe *pipeline.PanicError
+	assert.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, expectedErr, pe.Value)
+	assert.NotEmpty(t, pe.Stack)
+	assert.Equal(t, "step panicked: some error", err.Error())
+	assert.Equal(t, pipeline.RunStatusFailed, h.Status())
+}
+
+func TestRunAsync_GivenAStepPanickingWithAValue_WhenWaited_ThenItFailsWithThePanic(t *testing.T) {
+	h := pipeline.RunAsync[int, int](context.Background(), pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		panic("boom")
+	}), 1)
+
+	_, err := h.Wait(context.Background())
+
+	assert.EqualError(t, err, "step panicked: boom")
+	assert.Equal(t, pipeline.RunStatusFailed, h.Status())
+}
+
+func TestRunManager_GivenARunningID_WhenStartedAgain_ThenErrorIsReturned(t *testing.T) {
+	release := make(chan struct{})
+	runs := pipeline.NewRunManager[int, int](time.Hour)
+
+	h, err := runs.Start(context.Background(), "run", newBlockingUnit(release), 1)
+	assert.Nil(t, err)
+	_, err = runs.Start(context.Background(), "run", newBlockingUnit(release), 1)
+	assert.ErrorIs(t, err, pipeline.ErrRunInProgress)
+	assert.Equal(t, []string{"run"}, runs.Active())
+
+	close(release)
+	_, _ = h.Wait(context.Background())
+	assert.Empty(t, runs.Active())
+
+	got, ok := runs.Get("run")
+	assert.True(t, ok)
+	assert.Same(t, h, got)
+
+	replaced, err := runs.Start(context.Background(), "run", newBlockingUnit(release), 1)
+	assert.Nil(t, err)
+	assert.NotSame(t, h, replaced)
+}
+
+func TestRunManager_GivenARun_WhenStarted_ThenItCarriesItsID(t *testing.T) {
+	runs := pipeline.NewRunManager[int, string](time.Hour)
+
+	h, _ := runs.Start(context.Background(), "run", pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (string, error) {
+		return pipeline.RunIDFromContext(ctx), nil
+	}), 1)
+	res, _ := h.Wait(context.Background())
+
+	assert.Equal(t, "run", res)
+}
+
+func TestRunManager_GivenARun_WhenCanceledByID_ThenItIsCanceled(t *testing.T) {
+	runs := pipeline.NewRunManager[int, int](time.Hour)
+	h, _ := runs.Start(context.Background(), "run", newBlockingUnit(nil), 1)
+
+	assert.True(t, runs.Cancel("run"))
+	assert.False(t, runs.Cancel("unknown"))
+	_, _ = h.Wait(context.Background())
+
+	assert.Equal(t, pipeline.RunStatusCanceled, h.Status())
+}
+
+func TestRunManager_GivenAFinishedRun_WhenRetentionElapses_ThenItIsForgotten(t *testing.T) {
+	runs := pipeline.NewRunManager[int, int](time.Millisecond)
+	release := make(chan struct{})
+	close(release)
+	h, _ := runs.Start(context.Background(), "run", newBlockingUnit(release), 1)
+	_, _ = h.Wait(context.Background())
+
+	assert.Eventually(t, func() bool {
+		_, ok := runs.Get("run")
+		return !ok
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,272 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// CheckpointStore persists the checkpoints of pipeline runs, keyed by run ID and step path.
+	//
+	// Implementations must be safe for concurrent use, as steps may run concurrently.
+	CheckpointStore interface {
+		// Load the checkpoint of the step path in the run, or false if there's none
+		Load(ctx context.Context, runID, path string) ([]byte, bool, error)
+		// Save the checkpoint of the step path in the run, replacing any previous one
+		Save(ctx context.Context, runID, path string, data []byte) error
+		// Clear every checkpoint of the run, eg. once it finished successfully
+		Clear(ctx context.Context, runID string) error
+	}
+
+	// CheckpointCodec encodes the outputs of the steps into checkpoints, and decodes them back
+	CheckpointCodec interface {
+		Encode(v any) ([]byte, error)
+		Decode(data []byte, v any) error
+	}
+
+	// JSONCheckpointCodec encodes checkpoints as JSON
+	JSONCheckpointCodec struct{}
+
+	// GobCheckpointCodec encodes checkpoints with encoding/gob
+	GobCheckpointCodec struct{}
+
+	// FileCheckpointStore is a CheckpointStore persisting checkpoints as files of a directory, one directory per run.
+	// Files are named after the hash of the run ID and step path, so any of them can be stored.
+	FileCheckpointStore struct {
+		dir string
+	}
+
+	// CheckpointStep persists the output of a step once it completes, so re-runs (with the same run ID, see
+	// WithRunID) skip it and resume from its output.
+	//
+	// Checkpoints are keyed by run ID and step path. The path of a checkpoint step is the one it has in the step tree
+	// that was run (see Walk), eg. "sequential/1/checkpoint:download". Custom steps don't extend it, so the steps inside
+	// them aren't told apart: Validate the tree for catching checkpoint steps sharing a path.
+	// Runs without an ID aren't checkpointed.
+	//
+	//   step := pipeline.NewCheckpointStep("download", download, pipeline.NewFileCheckpointStore("/tmp/checkpoints"))
+	//   res, err := step.Run(pipeline.WithRunID(ctx, "batch-2024-01-01"), in)
+	CheckpointStep[I, O any] struct {
+		name  string
+		step  Step[I, O]
+		store CheckpointStore
+		codec CheckpointCodec
+	}
+
+	// runContext identifies the run a context belongs to, along with the path of the step it's running
+	runContext struct {
+		id   string
+		path []string
+	}
+
+	runContextKey struct{}
+)
+
+// WithRunID returns a copy of the context identifying the run, so checkpoint steps run with it persist their
+// outputs under it (and skip the steps already completed in a previous run with the same ID)
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runContextKey{}, runContext{id: runID})
+}
+
+// RunIDFromContext returns the run ID the context carries, or empty if it carries none
+func RunIDFromContext(ctx context.Context) string {
+	rc, _ := ctx.Value(runContextKey{}).(runContext)
+	return rc.id
+}
+
+// withStepPath returns a copy of the context for running the i-th child of the step, extending the path of the run
+// the context belongs to (see WithRunID). Only checkpoints need paths, so contexts of runs without an ID are
+// returned as they are.
+func withStepPath(ctx context.Context, step DrawableGraph, i int) context.Context {
+	rc, ok := ctx.Value(runContextKey{}).(runContext)
+	if !ok || len(rc.id) == 0 {
+		return ctx
+	}
+
+	segments := stepSegments(step, i)
+	path := make([]string, 0, len(rc.path)+len(segments))
+	path = append(path, rc.path...)
+	rc.path = append(path, segments...)
+	return context.WithValue(ctx, runContextKey{}, rc)
+}
+
+// NewCheckpointStep creates a step that checkpoints the given one as JSON in the store
+func NewCheckpointStep[I, O any](name string, step Step[I, O], store CheckpointStore) CheckpointStep[I, O] {
+	return NewCheckpointStepWithCodec(name, step, store, JSONCheckpointCodec{})
+}
+
+// NewCheckpointStepWithCodec creates a step that checkpoints the given one in the store, encoded with the codec
+func NewCheckpointStepWithCodec[I, O any](
+	name string,
+	step Step[I, O],
+	store CheckpointStore,
+	codec CheckpointCodec,
+) CheckpointStep[I, O] {
+	return CheckpointStep[I, O]{
+		name:  name,
+		step:  step,
+		store: store,
+		codec: codec,
+	}
+}
+
+// Name of this step
+func (c CheckpointStep[I, O]) Name() string {
+	return c.name
+}
+
+// Draw the inner step, stereotyping its activities as checkpointed
+func (c CheckpointStep[I, O]) Draw(graph Graph) {
+	DrawStereotyped(graph, "checkpoint", c.step.Draw)
+}
+
+// Kind of this step, see StepKindCheckpoint
+func (c CheckpointStep[I, O]) Kind() StepKind {
+	return StepKindCheckpoint
+}
+
+// Children of this step, the checkpointed one
+func (c CheckpointStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{c.step}
+}
+
+func (c CheckpointStep[I, O]) problems() []string {
+	var res []string
+	if c.step == nil {
+		res = append(res, "nil checkpointed step")
+	}
+	if c.store == nil {
+		res = append(res, "nil checkpoint store")
+	}
+	if c.codec == nil {
+		res = append(res, "nil checkpoint codec")
+	}
+	return res
+}
+
+// Run the inner step, unless the run already has a checkpoint of it. In such case its output is returned.
+// Once the inner step completes, its output is checkpointed.
+func (c CheckpointStep[I, O]) Run(ctx context.Context, in I) (O, error) {
+	rc, _ := ctx.Value(runContextKey{}).(runContext)
+	if len(rc.id) == 0 {
+		res, err := c.step.Run(ctx, in)
+		return res, prependStepPath(err, c, 0)
+	}
+
+	runID := rc.id
+	path := strings.Join(append(append([]string(nil), rc.path...), stepSegment(c)), "/")
+
+	data, ok, err := c.store.Load(ctx, runID, path)
+	if err != nil {
+		return *new(O), fmt.Errorf("checkpoint %s of run %s can't be loaded: %w", path, runID, err)
+	}
+	if ok {
+		var res O
+		if err := c.codec.Decode(data, &res); err != nil {
+			return *new(O), fmt.Errorf("checkpoint %s of run %s can't be decoded: %w", path, runID, err)
+		}
+		return res, nil
+	}
+
+	res, err := c.step.Run(withStepPath(ctx, c, 0), in)
+	if err != nil {
+		return res, prependStepPath(err, c, 0)
+	}
+
+	if data, err = c.codec.Encode(res); err != nil {
+		return res, fmt.Errorf("checkpoint %s of run %s can't be encoded: %w", path, runID, err)
+	}
+	if err = c.store.Save(ctx, runID, path, data); err != nil {
+		return res, fmt.Errorf("checkpoint %s of run %s can't be saved: %w", path, runID, err)
+	}
+	return res, nil
+}
+
+func (JSONCheckpointCodec) Encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCheckpointCodec) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (GobCheckpointCodec) Encode(v any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (GobCheckpointCodec) Decode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// NewFileCheckpointStore creates a store persisting checkpoints under the given directory
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		dir: dir,
+	}
+}
+
+func (f *FileCheckpointStore) Load(_ context.Context, runID, path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(f.file(runID, path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Save the checkpoint atomically, so a failure while saving it never leaves a partial checkpoint behind
+func (f *FileCheckpointStore) Save(_ context.Context, runID, path string, data []byte) error {
+	dir := f.runDir(runID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.file(runID, path))
+}
+
+func (f *FileCheckpointStore) Clear(_ context.Context, runID string) error {
+	return os.RemoveAll(f.runDir(runID))
+}
+
+func (f *FileCheckpointStore) runDir(runID string) string {
+	return filepath.Join(f.dir, checkpointFileName(runID))
+}
+
+func (f *FileCheckpointStore) file(runID, path string) string {
+	return filepath.Join(f.runDir(runID), checkpointFileName(path)+".checkpoint")
+}
+
+// checkpointFileName hashes the name into a single file name, which can't point outside its directory and is valid
+// in every file system no matter the characters or the length of the name (eg. step paths contain colons)
+func checkpointFileName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,272 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+type checkpointed struct {
+	Value int
+	Label string
+}
+
+func newCountingUnit(name string, calls *int, err error) pipeline.Step[int, int] {
+	return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+		*calls++
+		return i + 1, err
+	})
+}
+
+func TestCheckpointStep_GivenAFailedRun_WhenRerunWithTheSameID_ThenCompletedStepsAreSkipped(t *testing.T) {
+	expectedErr := errors.New("some error")
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	var firstCalls, secondCalls int
+	newPipeline := func(err error) pipeline.Step[int, int] {
+		return pipeline.NewSequentialStep[int, int, int](
+			pipeline.NewCheckpointStep("first", newCountingUnit("first", &firstCalls, nil), store),
+			pipeline.NewCheckpointStep("second", newCountingUnit("second", &secondCalls, err), store),
+		)
+	}
+	ctx := pipeline.WithRunID(context.Background(), "run")
+
+	_, err := newPipeline(expectedErr).Run(ctx, 1)
+	assert.ErrorIs(t, err, expectedErr)
+
+	res, err := newPipeline(nil).Run(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, res)
+	assert.Equal(t, 1, firstCalls)
+	assert.Equal(t, 2, secondCalls)
+
+	res, err = newPipeline(nil).Run(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, res)
+	assert.Equal(t, 1, firstCalls)
+	assert.Equal(t, 2, secondCalls)
+}
+
+func TestCheckpointStep_GivenNoRunID_WhenRun_ThenNothingIsCheckpointed(t *testing.T) {
+	dir := t.TempDir()
+	var calls int
+	step := pipeline.NewCheckpointStep("unit", newCountingUnit("unit", &calls, nil), pipeline.NewFileCheckpointStore(dir))
+
+	_, _ = step.Run(context.Background(), 1)
+	_, _ = step.Run(context.Background(), 1)
+
+	assert.Equal(t, 2, calls)
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCheckpointStep_GivenDifferentRunIDs_WhenRun_ThenTheyDontShareCheckpoints(t *testing.T) {
+	var calls int
+	step := pipeline.NewCheckpointStep("unit", newCountingUnit("unit", &calls, nil), pipeline.NewFileCheckpointStore(t.TempDir()))
+
+	_, _ = step.Run(pipeline.WithRunID(context.Background(), "a"), 1)
+	_, _ = step.Run(pipeline.WithRunID(context.Background(), "b"), 1)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestCheckpointStep_GivenNestedCheckpoints_WhenRun_ThenTheirPathsArePrefixed(t *testing.T) {
+	dir := t.TempDir()
+	var calls int
+	store := pipeline.NewFileCheckpointStore(dir)
+	step := pipeline.NewCheckpointStep("outer", pipeline.NewCheckpointStep("inner", newCountingUnit("unit", &calls, nil), store), store)
+
+	_, err := step.Run(pipeline.WithRunID(context.Background(), "run"), 1)
+
+	assert.Nil(t, err)
+	_, ok, err := store.Load(context.Background(), "run", "checkpoint:outer/checkpoint:inner")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	_, ok, _ = store.Load(context.Background(), "run", "checkpoint:outer")
+	assert.True(t, ok)
+}
+
+func TestCheckpointStep_GivenCheckpointsWithTheSameNameInDifferentBranches_WhenResumed_ThenTheyDontCollide(t *testing.T) {
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	newBranch := func(delta int) pipeline.Step[int, int] {
+		return pipeline.NewCheckpointStep("unit", pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+			return i + delta, nil
+		}), store)
+	}
+	step := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{newBranch(1), newBranch(10)}, func(ctx context.Context, a, b int) (int, error) {
+		return a + b, nil
+	})
+	ctx := pipeline.WithRunID(context.Background(), "run")
+
+	first, _ := step.Run(ctx, 1)
+	resumed, err := step.Run(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 13, first)
+	assert.Equal(t, 13, resumed)
+	_, ok, _ := store.Load(ctx, "run", "concurrent/1/checkpoint:unit")
+	assert.True(t, ok)
+}
+
+func TestCheckpointStep_GivenCheckpointsWithTheSameNameInSiblingSequentialSteps_WhenResumed_ThenTheyDontCollide(t *testing.T) {
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	newPipeline := func(name string, delta int) pipeline.Step[int, int] {
+		return pipeline.NewSequentialStep[int, int, int](
+			newIntrospectedUnit(name+"_start"),
+			pipeline.NewCheckpointStep("unit", pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+				return i + delta, nil
+			}), store),
+		)
+	}
+	step := pipeline.NewSequentialStep[int, int, int](newPipeline("first", 1), newPipeline("second", 10))
+	ctx := pipeline.WithRunID(context.Background(), "run")
+
+	first, _ := step.Run(ctx, 1)
+	resumed, err := step.Run(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.Nil(t, pipeline.Validate(step))
+	assert.Equal(t, 12, first)
+	assert.Equal(t, 12, resumed)
+	_, ok, _ := store.Load(ctx, "run", "sequential/1/sequential/1/checkpoint:unit")
+	assert.True(t, ok)
+}
+
+func TestCheckpointStep_GivenCheckpointsSharingAPath_WhenValidated_ThenTheyAreReported(t *testing.T) {
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	var calls int
+	step := pipeline.NewSequentialStep[int, int, int](
+		newIntrospectedUnit("start"),
+		chainStep[int]{steps: []pipeline.Step[int, int]{
+			pipeline.NewCheckpointStep("unit", newCountingUnit("first", &calls, nil), store),
+			pipeline.NewCheckpointStep("unit", newCountingUnit("second", &calls, nil), store),
+		}},
+	)
+
+	err := pipeline.Validate(step)
+
+	assert.Equal(t, []string{
+		"sequential/1/chain/checkpoint:unit: duplicate checkpoint path, also used by another checkpoint step",
+	}, validationMessages(t, err))
+}
+
+func TestCheckpointStep_GivenAGobCodec_WhenResumed_ThenTheOutputIsDecoded(t *testing.T) {
+	var calls int
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	step := pipeline.NewCheckpointStepWithCodec[int, checkpointed]("unit", pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (checkpointed, error) {
+		calls++
+		return checkpointed{Value: i, Label: "label"}, nil
+	}), store, pipeline.GobCheckpointCodec{})
+	ctx := pipeline.WithRunID(context.Background(), "run")
+
+	_, _ = step.Run(ctx, 1)
+	res, err := step.Run(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, checkpointed{Value: 1, Label: "label"}, res)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCheckpointStep_GivenACorruptedCheckpoint_WhenRun_ThenErrorIsReturned(t *testing.T) {
+	var calls int
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	_ = store.Save(context.Background(), "run", "checkpoint:unit", []byte("{corrupted"))
+	step := pipeline.NewCheckpointStep("unit", newCountingUnit("unit", &calls, nil), store)
+
+	_, err := step.Run(pipeline.WithRunID(context.Background(), "run"), 1)
+
+	assert.ErrorContains(t, err, "checkpoint checkpoint:unit of run run can't be decoded")
+	assert.Zero(t, calls)
+}
+
+func TestFileCheckpointStore_GivenCheckpoints_WhenCleared_ThenTheyAreRemoved(t *testing.T) {
+	ctx := context.Background()
+	store := pipeline.NewFileCheckpointStore(t.TempDir())
+	assert.Nil(t, store.Save(ctx, "run", "a/b", []byte("data")))
+
+	data, ok, err := store.Load(ctx, "run", "a/b")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("data"), data)
+
+	assert.Nil(t, store.Clear(ctx, "run"))
+	_, ok, err = store.Load(ctx, "run", "a/b")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestFileCheckpointStore_GivenRelativeNames_ThenTheyStayInsideTheDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "checkpoints")
+	store := pipeline.NewFileCheckpointStore(dir)
+
+	assert.Nil(t, store.Save(context.Background(), "..", "..", []byte("data")))
+
+	entries, err := os.ReadDir(root)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "checkpoints", entries[0].Name())
+}
+
+func TestFileCheckpointStore_GivenAnyPath_ThenItsFileNameIsPortable(t *testing.T) {
+	dir := t.TempDir()
+	store := pipeline.NewFileCheckpointStore(dir)
+	path := strings.Repeat("sequential/1/checkpoint:unit/", 20)
+
+	assert.Nil(t, store.Save(context.Background(), "run:1", path, []byte("data")))
+
+	runs, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, runs, 1)
+	files, err := os.ReadDir(filepath.Join(dir, runs[0].Name()))
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	for _, name := range []string{runs[0].Name(), files[0].Name()} {
+		assert.Regexp(t, `^[0-9a-f]+(\.checkpoint)?$`, name)
+		assert.LessOrEqual(t, len(name), 255)
+	}
+	data, ok, err := store.Load(context.Background(), "run:1", path)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("data"), data)
+}
+
+// chainStep is a custom step running its steps one after the other, which can be inspected
+type chainStep[T any] struct {
+	steps []pipeline.Step[T, T]
+}
+
+func (c chainStep[T]) Kind() pipeline.StepKind {
+	return "chain"
+}
+
+func (c chainStep[T]) Children() []pipeline.DrawableGraph {
+	res := make([]pipeline.DrawableGraph, len(c.steps))
+	for i, s := range c.steps {
+		res[i] = s
+	}
+	return res
+}
+
+func (c chainStep[T]) Draw(graph pipeline.Graph) {
+	for _, s := range c.steps {
+		s.Draw(graph)
+	}
+}
+
+func (c chainStep[T]) Run(ctx context.Context, in T) (T, error) {
+	var err error
+	for _, s := range c.steps {
+		if in, err = s.Run(ctx, in); err != nil {
+			return in, err
+		}
+	}
+	return in, nil
+}
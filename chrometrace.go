@@ -0,0 +1,218 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// ChromeTraceRecorder records step executions of a run and exports them in the Chrome trace-event JSON
+	// format, so they can be opened in chrome://tracing or Perfetto.
+	//
+	// It's both an Interceptor (recording every unit, see WithInterceptors) and a Tracer (recording
+	// composite steps decorated through a TracedStep). Each execution is exported as a complete event, and
+	// every goroutine spawned by a ConcurrentStep is exported as a separate track (thread), so nested
+	// executions appear as nested slices of their track. Tracks are only forked while the recorder is
+	// an interceptor of the run (see ForkInterceptor), else every execution is exported in the main track.
+	ChromeTraceRecorder struct {
+		origin time.Time
+		// sequence yields unique IDs for the tracks forked
+		sequence uint64
+
+		mux    sync.Mutex
+		events []ChromeTraceEvent
+		tracks map[uint64]int
+	}
+
+	// ChromeTraceEvent is a single event of the Chrome trace-event format
+	ChromeTraceEvent struct {
+		Name string `json:"name"`
+		// Category of the event
+		Cat string `json:"cat,omitempty"`
+		// Phase of the event, "X" for complete events and "M" for metadata ones
+		Ph string `json:"ph"`
+		// Ts is the start of the event, in microseconds since the recorder was created
+		Ts int64 `json:"ts"`
+		// Dur is the duration of the event in microseconds
+		Dur int64 `json:"dur,omitempty"`
+		Pid int   `json:"pid"`
+		// Tid is the track of the event
+		Tid  int            `json:"tid"`
+		Args map[string]any `json:"args,omitempty"`
+	}
+
+	chromeTraceSpan struct {
+		recorder *ChromeTraceRecorder
+		name     string
+		cat      string
+		track    uint64
+		start    time.Time
+
+		mux   sync.Mutex
+		attrs []Attribute
+		err   error
+	}
+
+	chromeTraceTrackContextKey struct {
+		recorder *ChromeTraceRecorder
+	}
+
+	chromeTraceUnitContextKey struct {
+		recorder *ChromeTraceRecorder
+	}
+)
+
+// NewChromeTraceRecorder creates a recorder whose timestamps are relative to its creation
+func NewChromeTraceRecorder() *ChromeTraceRecorder {
+	return &ChromeTraceRecorder{
+		origin: time.Now(),
+		tracks: map[uint64]int{0: 0}, // main track is always the first one
+	}
+}
+
+func (r *ChromeTraceRecorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := r.start(ctx, name, "composite")
+	return ContextWithSpan(ctx, s), s
+}
+
+func (r *ChromeTraceRecorder) Before(ctx context.Context, call StepCall) context.Context {
+	return context.WithValue(ctx, chromeTraceUnitContextKey{r}, r.start(ctx, call.Name, "unit"))
+}
+
+func (r *ChromeTraceRecorder) After(ctx context.Context, call StepCall) {
+	if s, ok := ctx.Value(chromeTraceUnitContextKey{r}).(*chromeTraceSpan); ok {
+		s.RecordError(call.Err)
+		s.End()
+	}
+}
+
+// Fork the track of the context, as the branch runs in a new goroutine
+func (r *ChromeTraceRecorder) Fork(ctx context.Context) context.Context {
+	return context.WithValue(ctx, chromeTraceTrackContextKey{r}, atomic.AddUint64(&r.sequence, 1))
+}
+
+// Events returns the recorded events sorted by their start time, preceded by the metadata events naming each track
+func (r *ChromeTraceRecorder) Events() []ChromeTraceEvent {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	res := make([]ChromeTraceEvent, 0, len(r.tracks)+len(r.events))
+	for track, tid := range r.tracks {
+		name := "main"
+		if track > 0 {
+			name = fmt.Sprintf("track %d", tid)
+		}
+		res = append(res, ChromeTraceEvent{
+			Name: "thread_name",
+			Ph:   "M",
+			Pid:  1,
+			Tid:  tid,
+			Args: map[string]any{"name": name},
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Tid < res[j].Tid
+	})
+
+	events := append([]ChromeTraceEvent(nil), r.events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Ts == events[j].Ts {
+			return events[i].Dur > events[j].Dur // parents first
+		}
+		return events[i].Ts < events[j].Ts
+	})
+	return append(res, events...)
+}
+
+// WriteTo writes the recorded events in the Chrome trace-event JSON format
+func (r *ChromeTraceRecorder) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(struct {
+		TraceEvents     []ChromeTraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string             `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     r.Events(),
+		DisplayTimeUnit: "ms",
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (r *ChromeTraceRecorder) start(ctx context.Context, name, cat string) *chromeTraceSpan {
+	return &chromeTraceSpan{
+		recorder: r,
+		name:     name,
+		cat:      cat,
+		track:    r.track(ctx),
+		start:    time.Now(),
+	}
+}
+
+// track the context runs in, the main one (zero) unless it was forked
+func (r *ChromeTraceRecorder) track(ctx context.Context) uint64 {
+	t, _ := ctx.Value(chromeTraceTrackContextKey{r}).(uint64)
+	return t
+}
+
+func (r *ChromeTraceRecorder) add(s *chromeTraceSpan, end time.Time) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	tid, ok := r.tracks[s.track]
+	if !ok { // map tracks into compact IDs, in order of appearance
+		tid = len(r.tracks)
+		r.tracks[s.track] = tid
+	}
+
+	var args map[string]any
+	if len(s.attrs) > 0 || s.err != nil {
+		args = make(map[string]any, len(s.attrs)+1)
+		for _, a := range s.attrs {
+			args[a.Key] = a.Value
+		}
+		if s.err != nil {
+			args["error"] = s.err.Error()
+		}
+	}
+
+	r.events = append(r.events, ChromeTraceEvent{
+		Name: s.name,
+		Cat:  s.cat,
+		Ph:   "X",
+		Ts:   s.start.Sub(r.origin).Microseconds(),
+		Dur:  end.Sub(s.start).Microseconds(),
+		Pid:  1,
+		Tid:  tid,
+		Args: args,
+	})
+}
+
+func (s *chromeTraceSpan) SetAttributes(attrs ...Attribute) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *chromeTraceSpan) RecordError(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.err = err
+}
+
+func (s *chromeTraceSpan) End() {
+	end := time.Now()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.recorder.add(s, end)
+}
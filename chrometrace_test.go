@@ -0,0 +1,96 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestChromeTraceRecorder_GivenAConcurrentRun_WhenRecorded_ThenGoroutinesAreSeparateTracks(t *testing.T) {
+	recorder := pipeline.NewChromeTraceRecorder()
+	unit := func(name string) pipeline.Step[int, int] {
+		return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+			time.Sleep(time.Millisecond)
+			return i, nil
+		})
+	}
+	step := pipeline.NewTracedStepWithTracer[int, int](
+		"root",
+		pipeline.NewSequentialStep[int, int, int](
+			unit("first"),
+			pipeline.NewConcurrentStep([]pipeline.Step[int, int]{unit("a"), unit("b")}, func(ctx context.Context, a, b int) (int, error) {
+				return a + b, nil
+			}),
+		),
+		recorder,
+	)
+
+	_, err := step.Run(pipeline.WithInterceptors(context.Background(), recorder), 1)
+
+	assert.Nil(t, err)
+	events := recorder.Events()
+	assert.Len(t, events, 7) // 3 tracks + 4 executions
+	for i, e := range events[:3] {
+		assert.Equal(t, "M", e.Ph)
+		assert.Equal(t, "thread_name", e.Name)
+		assert.Equal(t, i, e.Tid)
+	}
+	assert.Equal(t, "main", events[0].Args["name"])
+	assert.Equal(t, "track 1", events[1].Args["name"])
+
+	byName := make(map[string]pipeline.ChromeTraceEvent)
+	for _, e := range events[3:] {
+		assert.Equal(t, "X", e.Ph)
+		byName[e.Name] = e
+	}
+	assert.Equal(t, "composite", byName["root"].Cat)
+	assert.Equal(t, "unit", byName["first"].Cat)
+	assert.Equal(t, 0, byName["root"].Tid)
+	assert.Equal(t, 0, byName["first"].Tid)
+	assert.NotEqual(t, 0, byName["a"].Tid)
+	assert.NotEqual(t, 0, byName["b"].Tid)
+	assert.NotEqual(t, byName["a"].Tid, byName["b"].Tid)
+	for _, n := range []string{"first", "a", "b"} {
+		assert.GreaterOrEqual(t, byName[n].Ts, byName["root"].Ts)
+		assert.LessOrEqual(t, byName[n].Ts+byName[n].Dur, byName["root"].Ts+byName["root"].Dur)
+	}
+}
+
+func TestChromeTraceRecorder_GivenAFailingRun_WhenWritten_ThenTraceEventJSONIsWritten(t *testing.T) {
+	recorder := pipeline.NewChromeTraceRecorder()
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, errors.New("some error")
+	})
+	_, _ = step.Run(pipeline.WithInterceptors(context.Background(), recorder), 1)
+	var buf bytes.Buffer
+
+	_, err := recorder.WriteTo(&buf)
+
+	var trace struct {
+		TraceEvents []map[string]any `json:"traceEvents"`
+	}
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &trace))
+	assert.Len(t, trace.TraceEvents, 2)
+	assert.Equal(t, "unit", trace.TraceEvents[1]["name"])
+	assert.Equal(t, "X", trace.TraceEvents[1]["ph"])
+	assert.Equal(t, map[string]any{"error": "some error"}, trace.TraceEvents[1]["args"])
+}
+
+func TestChromeTraceRecorder_GivenASpanWithAttributes_WhenEnded_ThenTheyAreArgs(t *testing.T) {
+	recorder := pipeline.NewChromeTraceRecorder()
+
+	_, span := recorder.Start(context.Background(), "span")
+	span.SetAttributes(pipeline.NewAttribute("key", "value"))
+	span.End()
+
+	events := recorder.Events()
+	assert.Equal(t, map[string]any{"key": "value"}, events[1].Args)
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"fmt"
 )
 
 type (
@@ -18,8 +19,9 @@ type (
 
 	// concurrentResult is a discriminated union of a result or error.
 	concurrentResult[T any] struct {
-		Ret T
-		Err error
+		Index int
+		Ret   T
+		Err   error
 	}
 )
 
@@ -49,6 +51,37 @@ func (c ConcurrentStep[I, O]) Draw(graph Graph) {
 	}
 }
 
+// Kind of this step, see StepKindConcurrent
+func (c ConcurrentStep[I, O]) Kind() StepKind {
+	return StepKindConcurrent
+}
+
+// Children of this step, the ones run concurrently
+func (c ConcurrentStep[I, O]) Children() []DrawableGraph {
+	children := make([]DrawableGraph, len(c.steps))
+	for i, s := range c.steps {
+		children[i] = s
+	}
+	return children
+}
+
+func (c ConcurrentStep[I, O]) problems() []string {
+	if len(c.steps) == 0 {
+		return []string{"empty concurrent steps"}
+	}
+
+	var res []string
+	for i, s := range c.steps {
+		if s == nil {
+			res = append(res, fmt.Sprintf("nil step at index %d", i))
+		}
+	}
+	if c.reduce == nil && len(c.steps) > 1 {
+		res = append(res, "nil reducer")
+	}
+	return res
+}
+
 // Run the step concurrently, if one of them fails an error will be returned.
 //
 // This step waits for all of the concurrent ones to finish.
@@ -71,7 +104,7 @@ func (c ConcurrentStep[I, O]) Run(ctx context.Context, in I) (O, error) {
 		}
 
 		if v.Err != nil {
-			err = v.Err // step errored.
+			err = prependStepPath(v.Err, c, v.Index) // step errored.
 			continue
 		}
 
@@ -100,10 +133,10 @@ func (c ConcurrentStep[I, O]) runConcurrently(
 	ch := make(chan concurrentResult[O], len(workers))
 	if len(workers) > 1 {
 		for i := 0; i < len(workers); i++ {
-			go c.runStep(ctx, in, workers[i], ch)
+			go c.runStep(withStepPath(interceptFork(ctx), c, i), in, i, workers[i], ch)
 		}
 	} else { // avoid concurrency, no need to spawn and wait just use current
-		c.runStep(ctx, in, workers[0], ch)
+		c.runStep(withStepPath(ctx, c, 0), in, 0, workers[0], ch)
 	}
 	return ch
 }
@@ -111,13 +144,15 @@ func (c ConcurrentStep[I, O]) runConcurrently(
 func (c ConcurrentStep[I, O]) runStep(
 	ctx context.Context,
 	in I,
+	index int,
 	step Step[I, O],
 	ch chan<- concurrentResult[O],
 ) {
 
 	res, err := step.Run(ctx, in)
 	ch <- concurrentResult[O]{
-		Ret: res,
-		Err: err,
+		Index: index,
+		Ret:   res,
+		Err:   err,
 	}
 }
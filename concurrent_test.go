@@ -186,7 +186,7 @@ func TestConcurrentStep_GivenStepsWithErrors_WhenRun_ThenAllStepsAreRun(t *testi
 
 	v, err := cstep.Run(context.Background(), 1)
 
-	assert.Equal(t, expectedErr, err)
+	assert.ErrorIs(t, err, expectedErr)
 	assert.Equal(t, count32(10), times)
 	assert.Equal(t, 0, v)
 }
@@ -52,16 +52,53 @@ func (c ConditionalStep[I, O]) Draw(graph Graph) {
 	)
 }
 
+// Name of the statement of this step, or empty if it has none
+func (c ConditionalStep[I, O]) Name() string {
+	if c.statement == nil {
+		return ""
+	}
+	return c.statement.Name()
+}
+
+// Kind of this step, see StepKindConditional
+func (c ConditionalStep[I, O]) Kind() StepKind {
+	return StepKindConditional
+}
+
+// Children of this step, the true and false branches (which may be nil)
+func (c ConditionalStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{c.trueCn, c.falseCn}
+}
+
+func (c ConditionalStep[I, O]) problems() []string {
+	var res []string
+	if c.statement == nil {
+		res = append(res, "nil statement")
+	} else if s, ok := c.statement.(interface{ evaluable() bool }); ok && !s.evaluable() {
+		res = append(res, fmt.Sprintf("statement %q without evaluation, it always evaluates to false", c.statement.Name()))
+	}
+	if c.trueCn == nil {
+		res = append(res, "nil true branch")
+	}
+	if c.falseCn == nil {
+		res = append(res, "nil false branch")
+	}
+	return res
+}
+
 // Run one of the provided steps depending on the statement's evaluation.
 func (c ConditionalStep[I, O]) Run(ctx context.Context, in I) (O, error) {
 	ok := c.statement.Evaluate(ctx, in)
+	interceptDecision(ctx, c.statement, ok)
 	if ok {
 		if c.trueCn != nil {
-			return c.trueCn.Run(ctx, in)
+			res, err := c.trueCn.Run(withStepPath(ctx, c, 0), in)
+			return res, prependStepPath(err, c, 0)
 		}
 	} else {
 		if c.falseCn != nil {
-			return c.falseCn.Run(ctx, in)
+			res, err := c.falseCn.Run(withStepPath(ctx, c, 1), in)
+			return res, prependStepPath(err, c, 1)
 		}
 	}
 	return *new(O), fmt.Errorf("conditional step '%s' cannot run since the evaluated condition (%v) has a nil branch", c.statement.Name(), ok)
@@ -172,7 +172,7 @@ func TestConditionalStep_GivenStatementTrueWithTrueError_WhenRun_TrueErrorReturn
 
 	_, err := step.Run(context.Background(), 1)
 
-	assert.Equal(t, trueErr, err)
+	assert.ErrorIs(t, err, trueErr)
 }
 
 func TestConditionalStep_GivenStatementFalseWithFalseError_WhenRun_FalseErrorReturned(t *testing.T) {
@@ -187,7 +187,7 @@ func TestConditionalStep_GivenStatementFalseWithFalseError_WhenRun_FalseErrorRet
 
 	_, err := step.Run(context.Background(), 1)
 
-	assert.Equal(t, falseErr, err)
+	assert.ErrorIs(t, err, falseErr)
 }
 
 func TestConditionalStep_GivenAGraphToDrawWithAnonymouseStatement_WhenDrawn_ThenConditionGetsEmptyName(t *testing.T) {
@@ -0,0 +1,201 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DOTRankDirTopBottom lays out the graph from top to bottom
+	DOTRankDirTopBottom DOTRankDir = "TB"
+	// DOTRankDirLeftRight lays out the graph from left to right
+	DOTRankDirLeftRight DOTRankDir = "LR"
+	// DOTRankDirBottomTop lays out the graph from bottom to top
+	DOTRankDirBottomTop DOTRankDir = "BT"
+	// DOTRankDirRightLeft lays out the graph from right to left
+	DOTRankDirRightLeft DOTRankDir = "RL"
+
+	// IDs of the start and end nodes of DOT graphs
+	dotStartID = "start"
+	dotEndID   = "stop"
+)
+
+type (
+	// DOTRankDir is the direction a DOT graph is laid out
+	DOTRankDir string
+
+	// DOTOptions available when drawing a DOT graph
+	DOTOptions struct {
+		// RankDir of the graph, by default we will use DOTRankDirTopBottom
+		RankDir DOTRankDir
+		// Cluster composite steps (decisions and concurrencies) into their own subgraph. Only their nodes are declared
+		// inside it, as Graphviz makes every node an edge of a subgraph references a member of it.
+		Cluster bool
+	}
+
+	// DOTGraph represents a graph that can render itself into Graphviz DOT.
+	//
+	// Activities are drawn as boxes, decisions as diamonds with yes/no labeled edges and concurrencies
+	// as fork/join bars. Node IDs are assigned sequentially in drawing order, so drawing the same
+	// step always yields the same output.
+	DOTGraph struct {
+		Options DOTOptions
+
+		sb       strings.Builder
+		count    int
+		clusters int
+		depth    int
+		// edges of the clusters being drawn, written at the top level once the outermost one is closed
+		edges strings.Builder
+		// tails are the nodes (and the label of their edge) that will be linked to the next node added
+		tails []graphTail
+	}
+)
+
+// NewDOTGraph creates a Graphviz DOT diagram that represents one, as specified
+func NewDOTGraph(options DOTOptions) *DOTGraph {
+	if len(options.RankDir) == 0 {
+		options.RankDir = DOTRankDirTopBottom
+	}
+
+	return &DOTGraph{
+		Options: options,
+		depth:   1,
+		tails:   []graphTail{{id: dotStartID}},
+	}
+}
+
+func (d *DOTGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	d.cluster(statement, func() {
+		id := d.node(statement, "shape=diamond")
+
+		d.tails = []graphTail{{id: id, label: "yes"}}
+		yes(d)
+		yesTails := d.tails
+
+		d.tails = []graphTail{{id: id, label: "no"}}
+		no(d)
+
+		d.tails = append(yesTails, d.tails...)
+	})
+}
+
+func (d *DOTGraph) AddConcurrency(forks ...GraphDrawer) {
+	if len(forks) == 0 {
+		return
+	}
+
+	d.cluster("", func() {
+		fork := d.bar()
+		var tails []graphTail
+		for _, f := range forks {
+			d.tails = []graphTail{{id: fork}}
+			f(d)
+			tails = append(tails, d.tails...)
+		}
+
+		d.tails = tails
+		d.bar()
+	})
+}
+
+func (d *DOTGraph) AddActivity(label string) {
+	d.node(label, "shape=box, style=rounded")
+}
+
+func (d *DOTGraph) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph pipeline {\n")
+	sb.WriteString(fmt.Sprintf("    rankdir=%s;\n", d.Options.RankDir))
+	sb.WriteString(fmt.Sprintf("    %s [shape=circle, style=filled, fillcolor=black, label=\"\", width=0.25];\n", dotStartID))
+
+	sb.WriteString(d.sb.String())
+
+	sb.WriteString(fmt.Sprintf("    %s [shape=doublecircle, style=filled, fillcolor=black, label=\"\", width=0.2];\n", dotEndID))
+	for _, t := range d.tails {
+		sb.WriteString("    " + dotEdge(t, dotEndID))
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// cluster draws the given function inside a subgraph cluster, if clustering is enabled
+func (d *DOTGraph) cluster(label string, draw func()) {
+	if !d.Options.Cluster {
+		draw()
+		return
+	}
+
+	d.clusters++
+	d.line(fmt.Sprintf("subgraph cluster_%d {", d.clusters))
+	d.depth++
+	d.line(fmt.Sprintf("label=\"%s\";", escapeDOT(label)))
+	d.line("style=dashed;")
+
+	draw()
+
+	d.depth--
+	d.line("}")
+
+	if d.depth == 1 {
+		d.sb.WriteString(d.edges.String())
+		d.edges.Reset()
+	}
+}
+
+// node adds a node with the given label and attributes, linking the pending tails to it
+func (d *DOTGraph) node(label, attrs string) string {
+	d.count++
+	id := fmt.Sprintf("n%d", d.count)
+
+	d.line(fmt.Sprintf("%s [%s, label=\"%s\"];", id, attrs, escapeDOT(label)))
+	d.link(id)
+	return id
+}
+
+// bar adds a fork/join bar, linking the pending tails to it
+func (d *DOTGraph) bar() string {
+	d.count++
+	id := fmt.Sprintf("n%d", d.count)
+
+	d.line(fmt.Sprintf("%s [shape=box, style=filled, fillcolor=black, label=\"\", height=0.05, width=1.5];", id))
+	d.link(id)
+	return id
+}
+
+// link the pending tails to the node. Inside clusters the edges are deferred, so they are written at the top level
+func (d *DOTGraph) link(id string) {
+	for _, t := range d.tails {
+		if d.depth > 1 {
+			d.edges.WriteString("    " + dotEdge(t, id))
+		} else {
+			d.line(strings.TrimSuffix(dotEdge(t, id), "\n"))
+		}
+	}
+	d.tails = []graphTail{{id: id}}
+}
+
+func (d *DOTGraph) line(s string) {
+	d.sb.WriteString(strings.Repeat("    ", d.depth))
+	d.sb.WriteString(s)
+	d.sb.WriteString("\n")
+}
+
+func dotEdge(from graphTail, to string) string {
+	if len(from.label) == 0 {
+		return fmt.Sprintf("%s -> %s;\n", from.id, to)
+	}
+	return fmt.Sprintf("%s -> %s [label=\"%s\"];\n", from.id, to, escapeDOT(from.label))
+}
+
+// escapeDOT escapes a label so it can be safely placed between double quotes
+func escapeDOT(label string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	).Replace(label)
+}
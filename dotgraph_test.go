@@ -0,0 +1,100 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestDOTGraph_GivenAGraph_WhenStringRepresentationIsAsked_ThenCompleteDOTIsRepresented(t *testing.T) {
+	diagram := pipeline.NewDOTGraph(pipeline.DOTOptions{})
+	diagram.AddActivity("beginning")
+	diagram.AddConcurrency(func(graph pipeline.Graph) {
+		graph.AddActivity("branch 1")
+	}, func(graph pipeline.Graph) {
+		graph.AddDecision("is this a test?", func(graph pipeline.Graph) {
+			graph.AddActivity("yes, this is a test")
+		}, func(graph pipeline.Graph) {})
+	})
+
+	content := diagram.String()
+
+	assert.Equal(t, `digraph pipeline {
+    rankdir=TB;
+    start [shape=circle, style=filled, fillcolor=black, label="", width=0.25];
+    n1 [shape=box, style=rounded, label="beginning"];
+    start -> n1;
+    n2 [shape=box, style=filled, fillcolor=black, label="", height=0.05, width=1.5];
+    n1 -> n2;
+    n3 [shape=box, style=rounded, label="branch 1"];
+    n2 -> n3;
+    n4 [shape=diamond, label="is this a test?"];
+    n2 -> n4;
+    n5 [shape=box, style=rounded, label="yes, this is a test"];
+    n4 -> n5 [label="yes"];
+    n6 [shape=box, style=filled, fillcolor=black, label="", height=0.05, width=1.5];
+    n3 -> n6;
+    n5 -> n6;
+    n4 -> n6 [label="no"];
+    stop [shape=doublecircle, style=filled, fillcolor=black, label="", width=0.2];
+    n6 -> stop;
+}
+`, content)
+}
+
+func TestDOTGraph_GivenClusteringAndRankDir_WhenDrawn_ThenCompositesAreClusteredWithTheirEdgesAtTheTopLevel(t *testing.T) {
+	diagram := pipeline.NewDOTGraph(pipeline.DOTOptions{
+		RankDir: pipeline.DOTRankDirLeftRight,
+		Cluster: true,
+	})
+	diagram.AddDecision("cond", func(graph pipeline.Graph) {
+		graph.AddConcurrency(func(graph pipeline.Graph) {
+			graph.AddActivity("a")
+		})
+	}, func(graph pipeline.Graph) {})
+
+	content := diagram.String()
+
+	assert.Equal(t, `digraph pipeline {
+    rankdir=LR;
+    start [shape=circle, style=filled, fillcolor=black, label="", width=0.25];
+    subgraph cluster_1 {
+        label="cond";
+        style=dashed;
+        n1 [shape=diamond, label="cond"];
+        subgraph cluster_2 {
+            label="";
+            style=dashed;
+            n2 [shape=box, style=filled, fillcolor=black, label="", height=0.05, width=1.5];
+            n3 [shape=box, style=rounded, label="a"];
+            n4 [shape=box, style=filled, fillcolor=black, label="", height=0.05, width=1.5];
+        }
+    }
+    start -> n1;
+    n1 -> n2 [label="yes"];
+    n2 -> n3;
+    n3 -> n4;
+    stop [shape=doublecircle, style=filled, fillcolor=black, label="", width=0.2];
+    n4 -> stop;
+    n1 -> stop [label="no"];
+}
+`, content)
+}
+
+func TestDOTGraph_GivenAGraph_WhenAddingZeroConcurrentCases_ThenNothingHappens(t *testing.T) {
+	diagram := pipeline.NewDOTGraph(pipeline.DOTOptions{})
+	diagram.AddConcurrency()
+
+	assert.Equal(t, pipeline.NewDOTGraph(pipeline.DOTOptions{}).String(), diagram.String())
+}
+
+func TestDOTGraph_GivenLabelsWithSpecialCharacters_WhenAdded_ThenTheyAreEscaped(t *testing.T) {
+	diagram := pipeline.NewDOTGraph(pipeline.DOTOptions{})
+	diagram.AddActivity("say \"hi\" \\o/\nplease")
+
+	content := diagram.String()
+
+	assert.Contains(t, content, `n1 [shape=box, style=rounded, label="say \"hi\" \\o/\nplease"];`)
+}
@@ -1,5 +1,7 @@
 package pipeline
 
+import "fmt"
+
 type (
 	// DrawableGraph is a contract for drawing in graphs
 	DrawableGraph interface {
@@ -7,6 +9,11 @@ type (
 	}
 
 	// Graph interface allowing to create a representation/drawing of a graph
+	//
+	// Graphs may also implement any of the optional LoopGraph, SwitchGraph, NoteGraph, ErrorGraph and
+	// PartitionGraph interfaces to support a richer vocabulary. Steps should draw through the DrawLoop,
+	// DrawSwitch, DrawNote, DrawErrorHandler and DrawPartition helpers, which fall back to the basic
+	// vocabulary when a graph doesn't support them.
 	Graph interface {
 		// AddConcurrency branching as many times as needed (each branch is a concurrent/fork 'node')
 		AddConcurrency(branches ...GraphDrawer)
@@ -16,6 +23,175 @@ type (
 		AddActivity(label string)
 	}
 
+	// LoopGraph is a graph that can represent loops
+	LoopGraph interface {
+		// AddLoop of a body that is repeated while the condition holds
+		AddLoop(condition string, body GraphDrawer)
+	}
+
+	// SwitchGraph is a graph that can represent N-way branchings
+	SwitchGraph interface {
+		// AddSwitch from a given statement, allowing inner graphs for each of its cases
+		AddSwitch(statement string, cases ...GraphCase)
+	}
+
+	// NoteGraph is a graph that can attach notes to its activities
+	NoteGraph interface {
+		// AddNote attached to the last added activity
+		AddNote(text string)
+	}
+
+	// ErrorGraph is a graph that can represent error (exception) edges
+	ErrorGraph interface {
+		// AddErrorHandler drawing a body whose failures (described by the label) lead to the handler
+		AddErrorHandler(label string, body GraphDrawer, handler GraphDrawer)
+	}
+
+	// PartitionGraph is a graph that can group activities into named partitions
+	PartitionGraph interface {
+		// AddPartition with the given name, grouping what the body draws
+		AddPartition(name string, body GraphDrawer)
+	}
+
+	// StyledGraph is a graph that can style its activities
+	StyledGraph interface {
+		// AddStyledActivity creates an action entry with the given style
+		AddStyledActivity(label string, style ActivityStyle)
+	}
+
+	// StereotypedGraph is a graph that can stereotype every activity drawn in a part of it
+	StereotypedGraph interface {
+		// AddStereotyped draws the body, stereotyping every activity it adds (even nested ones)
+		AddStereotyped(stereotype string, body GraphDrawer)
+	}
+
+	// MetadataGraph is a graph that can describe its activities with the metadata of the steps drawing them
+	MetadataGraph interface {
+		// AddDescribedActivity creates an action entry with the given style, described by the metadata
+		AddDescribedActivity(label string, style ActivityStyle, metadata StepMetadata)
+	}
+
+	// ActivityStyle of an activity
+	ActivityStyle struct {
+		// Color of the activity (eg. "#palegreen" or "palegreen"), by default the graph decides it
+		Color string
+		// Stereotypes of the activity, describing the kind of steps that draw it, innermost first.
+		//
+		// The steps of this package stereotype their activities with their kind: units with "unit" and collapsed
+		// groups with "group". Decorators stereotype every activity of the step they wrap through DrawStereotyped,
+		// with "traced", "metrics", "checkpoint" or "halt_boundary". Eg. a traced unit is <<unit, traced>>
+		Stereotypes []string
+	}
+
+	// GraphCase is a labeled branch of a switch
+	GraphCase struct {
+		Label string
+		Draw  GraphDrawer
+	}
+
 	// GraphDrawer alias for Draw(Graph) functions
 	GraphDrawer = func(Graph)
+
+	// graphTail is a node whose outgoing edge (optionally labeled) is pending to be linked to the next one.
+	// Used by graphs that are built as nodes and edges instead of blocks.
+	graphTail struct {
+		id    string
+		label string
+	}
 )
+
+// DrawLoop draws a loop in the graph. If it isn't a LoopGraph, the loop is drawn as a decision
+// whose yes branch is the body.
+func DrawLoop(graph Graph, condition string, body GraphDrawer) {
+	if g, ok := graph.(LoopGraph); ok {
+		g.AddLoop(condition, body)
+		return
+	}
+	graph.AddDecision(condition, body, func(Graph) {})
+}
+
+// DrawSwitch draws a switch in the graph. If it isn't a SwitchGraph, the switch is drawn as nested
+// decisions (one per case).
+func DrawSwitch(graph Graph, statement string, cases ...GraphCase) {
+	if g, ok := graph.(SwitchGraph); ok {
+		g.AddSwitch(statement, cases...)
+		return
+	}
+	drawSwitchAsDecisions(graph, statement, cases)
+}
+
+// DrawNote attaches a note to the last activity of the graph. If it isn't a NoteGraph, nothing is drawn.
+func DrawNote(graph Graph, text string) {
+	if g, ok := graph.(NoteGraph); ok {
+		g.AddNote(text)
+	}
+}
+
+// DrawErrorHandler draws a body whose failures lead to the handler. If the graph isn't an ErrorGraph,
+// the body is drawn followed by a decision (labeled as given) whose yes branch is the handler.
+func DrawErrorHandler(graph Graph, label string, body GraphDrawer, handler GraphDrawer) {
+	if g, ok := graph.(ErrorGraph); ok {
+		g.AddErrorHandler(label, body, handler)
+		return
+	}
+	body(graph)
+	graph.AddDecision(label, handler, func(Graph) {})
+}
+
+// DrawPartition draws a named group in the graph. If it isn't a PartitionGraph, the body is drawn
+// without grouping it.
+func DrawPartition(graph Graph, name string, body GraphDrawer) {
+	if g, ok := graph.(PartitionGraph); ok {
+		g.AddPartition(name, body)
+		return
+	}
+	body(graph)
+}
+
+// DrawStyledActivity draws an activity with the given style. If the graph isn't a StyledGraph, the activity
+// is drawn without styling it.
+func DrawStyledActivity(graph Graph, label string, style ActivityStyle) {
+	if g, ok := graph.(StyledGraph); ok {
+		g.AddStyledActivity(label, style)
+		return
+	}
+	graph.AddActivity(label)
+}
+
+// DrawDescribedActivity draws an activity with the given style, described by the metadata. If the graph isn't a
+// MetadataGraph, the activity is drawn followed by a note with the metadata.
+func DrawDescribedActivity(graph Graph, label string, style ActivityStyle, metadata StepMetadata) {
+	if g, ok := graph.(MetadataGraph); ok {
+		g.AddDescribedActivity(label, style, metadata)
+		return
+	}
+	DrawStyledActivity(graph, label, style)
+	if !metadata.IsZero() {
+		DrawNote(graph, metadata.String())
+	}
+}
+
+// DrawStereotyped draws the body, stereotyping every activity it adds. If the graph isn't a StereotypedGraph, the
+// body is drawn without stereotyping it.
+//
+// The body is drawn in the same graph, so decorators stereotyping the steps they wrap don't hide the graph from them.
+func DrawStereotyped(graph Graph, stereotype string, body GraphDrawer) {
+	if g, ok := graph.(StereotypedGraph); ok {
+		g.AddStereotyped(stereotype, body)
+		return
+	}
+	body(graph)
+}
+
+func drawSwitchAsDecisions(graph Graph, statement string, cases []GraphCase) {
+	if len(cases) == 0 {
+		return
+	}
+	graph.AddDecision(
+		fmt.Sprintf("%s is %s", statement, cases[0].Label),
+		cases[0].Draw,
+		func(graph Graph) {
+			drawSwitchAsDecisions(graph, statement, cases[1:])
+		},
+	)
+}
@@ -1,6 +1,9 @@
 package pipeline_test
 
 import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/saantiaguilera/go-pipeline"
@@ -26,3 +29,109 @@ func (m *mockGraph) String() string {
 	args := m.Called()
 	return args.String(0)
 }
+
+func TestDrawLoop_GivenAGraphWithoutLoops_WhenDrawing_ThenADecisionIsDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	graph.On("AddDecision", "has more", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(pipeline.GraphDrawer)(graph)
+		args.Get(2).(pipeline.GraphDrawer)(graph)
+	}).Once()
+	graph.On("AddActivity", "body").Once()
+
+	pipeline.DrawLoop(graph, "has more", func(graph pipeline.Graph) {
+		graph.AddActivity("body")
+	})
+
+	graph.AssertExpectations(t)
+}
+
+func TestDrawSwitch_GivenAGraphWithoutSwitches_WhenDrawing_ThenNestedDecisionsAreDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	graph.On("AddDecision", "choice is A", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(pipeline.GraphDrawer)(graph)
+		args.Get(2).(pipeline.GraphDrawer)(graph)
+	}).Once()
+	graph.On("AddDecision", "choice is B", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(pipeline.GraphDrawer)(graph)
+		args.Get(2).(pipeline.GraphDrawer)(graph)
+	}).Once()
+	graph.On("AddActivity", "a").Once()
+	graph.On("AddActivity", "b").Once()
+
+	pipeline.DrawSwitch(
+		graph,
+		"choice",
+		pipeline.GraphCase{Label: "A", Draw: func(graph pipeline.Graph) { graph.AddActivity("a") }},
+		pipeline.GraphCase{Label: "B", Draw: func(graph pipeline.Graph) { graph.AddActivity("b") }},
+	)
+
+	graph.AssertExpectations(t)
+}
+
+func TestDrawNote_GivenAGraphWithoutNotes_WhenDrawing_ThenNothingIsDrawn(t *testing.T) {
+	graph := new(mockGraph)
+
+	pipeline.DrawNote(graph, "some note")
+
+	graph.AssertExpectations(t)
+}
+
+func TestDrawErrorHandler_GivenAGraphWithoutErrorEdges_WhenDrawing_ThenBodyAndADecisionAreDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	graph.On("AddActivity", "body").Once()
+	graph.On("AddDecision", "errored", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(pipeline.GraphDrawer)(graph)
+		args.Get(2).(pipeline.GraphDrawer)(graph)
+	}).Once()
+	graph.On("AddActivity", "handler").Once()
+
+	pipeline.DrawErrorHandler(graph, "errored", func(graph pipeline.Graph) {
+		graph.AddActivity("body")
+	}, func(graph pipeline.Graph) {
+		graph.AddActivity("handler")
+	})
+
+	graph.AssertExpectations(t)
+}
+
+func TestDrawPartition_GivenAGraphWithoutPartitions_WhenDrawing_ThenBodyIsDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	graph.On("AddActivity", "body").Once()
+
+	pipeline.DrawPartition(graph, "group", func(graph pipeline.Graph) {
+		graph.AddActivity("body")
+	})
+
+	graph.AssertExpectations(t)
+}
+
+func TestDrawStereotyped_GivenAGraphWithoutStereotypes_WhenDrawing_ThenBodyIsDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	graph.On("AddActivity", "body").Once()
+
+	pipeline.DrawStereotyped(graph, "traced", func(graph pipeline.Graph) {
+		graph.AddActivity("body")
+	})
+
+	graph.AssertExpectations(t)
+}
+
+func TestDrawStereotyped_GivenADecoratedCustomStep_WhenDrawn_ThenItIsGivenTheSameGraph(t *testing.T) {
+	graph := pipeline.NewUMLGraph()
+	inner := new(mockStep[int, int])
+	inner.On("Draw", graph).Once()
+
+	pipeline.NewTracedStepWithTracer[int, int]("traced", inner, pipeline.NewRecordingTracer()).Draw(graph)
+
+	inner.AssertExpectations(t)
+}
+
+func TestDrawLoop_GivenALoopGraph_WhenDrawing_ThenTheLoopIsDrawn(t *testing.T) {
+	graph := pipeline.NewUMLGraph()
+
+	pipeline.DrawLoop(graph, "has more", func(graph pipeline.Graph) {
+		graph.AddActivity("body")
+	})
+
+	assert.Contains(t, graph.String(), "\nwhile (has more) is (yes)\n:body;\nendwhile (no)\n")
+}
@@ -15,7 +15,8 @@ type (
 )
 
 func (c fallbackStep[I, O]) Draw(graph pipeline.Graph) {
-	graph.AddDecision(
+	pipeline.DrawErrorHandler(
+		graph,
 		"errored",
 		func(graph pipeline.Graph) {
 			if c.step != nil {
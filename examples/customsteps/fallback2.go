@@ -18,7 +18,8 @@ type (
 )
 
 func (c fallback2Step[I, E, O]) Draw(graph pipeline.Graph) {
-	graph.AddDecision(
+	pipeline.DrawErrorHandler(
+		graph,
 		"errored",
 		func(graph pipeline.Graph) {
 			if c.step != nil {
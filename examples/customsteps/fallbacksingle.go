@@ -15,7 +15,8 @@ type (
 )
 
 func (c singlefallback[I, O]) Draw(graph pipeline.Graph) {
-	graph.AddDecision(
+	pipeline.DrawErrorHandler(
+		graph,
 		"errored",
 		func(graph pipeline.Graph) {
 			graph.AddActivity(c.unit.Name())
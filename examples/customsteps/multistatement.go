@@ -24,31 +24,23 @@ type (
 )
 
 func (c multiStatementStep[I, O]) Draw(graph pipeline.Graph) {
-	graph.AddDecision(
-		"choice A",
-		func(graph pipeline.Graph) {
-			if c.choiceA != nil {
-				c.choiceA.Draw(graph)
-			}
-		},
-		func(graph pipeline.Graph) {
-			graph.AddDecision(
-				"choice B else C",
-				func(graph pipeline.Graph) {
-					if c.choiceB != nil {
-						c.choiceB.Draw(graph)
-					}
-				},
-				func(g pipeline.Graph) {
-					if c.choiceC != nil {
-						c.choiceC.Draw(graph)
-					}
-				},
-			)
-		},
+	pipeline.DrawSwitch(
+		graph,
+		"choice",
+		pipeline.GraphCase{Label: "A", Draw: c.drawChoice(c.choiceA)},
+		pipeline.GraphCase{Label: "B", Draw: c.drawChoice(c.choiceB)},
+		pipeline.GraphCase{Label: "C", Draw: c.drawChoice(c.choiceC)},
 	)
 }
 
+func (c multiStatementStep[I, O]) drawChoice(choice pipeline.Step[I, O]) pipeline.GraphDrawer {
+	return func(graph pipeline.Graph) {
+		if choice != nil {
+			choice.Draw(graph)
+		}
+	}
+}
+
 func (c multiStatementStep[I, O]) Run(ctx context.Context, in I) (O, error) {
 	switch c.stmt(ctx, in) {
 	case exampleChoiceA:
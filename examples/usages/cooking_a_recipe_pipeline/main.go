@@ -4,7 +4,6 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"os"
 
 	"github.com/saantiaguilera/go-pipeline"
 )
@@ -92,15 +91,16 @@ func Graph() pipeline.Step[MealMaterials, Dish] {
 		},
 	)
 
-	// Create subgraph for making salad and cooking meat
-	makeSalad := pipeline.NewSequentialStep[MealMaterials, Vegetables, Salad](
+	// Create subgraph for making salad and cooking meat. Each of them is grouped, so they are drawn as partitions
+	// and scope the errors (and traces) of their steps
+	makeSalad := pipeline.NewGroupStep[MealMaterials, Salad]("make_salad", pipeline.NewSequentialStep[MealMaterials, Vegetables, Salad](
 		processVegetables,
 		newMakeSaladStep(),
-	)
-	cookMeat := pipeline.NewSequentialStep[MealMaterials, CookingTools, CookedMeat](
+	))
+	cookMeat := pipeline.NewGroupStep[MealMaterials, CookedMeat]("cook_meat", pipeline.NewSequentialStep[MealMaterials, CookingTools, CookedMeat](
 		processMeat,
 		newCookMeatStep(),
-	)
+	))
 
 	// create complete graph that serves the meal.
 	return pipeline.NewSequentialStep[MealMaterials, DishContents, Dish](
@@ -119,11 +119,10 @@ func RunGraphRendering() {
 		renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
 			Type: pipeline.UMLFormatSVG,
 		})
-		file, _ := os.Create("template.svg")
 
 		Graph().Draw(diagram)
 
-		err := renderer.Render(diagram, file)
+		err := pipeline.RenderToFile(context.Background(), renderer, diagram, "template.svg")
 
 		if err != nil {
 			panic(err)
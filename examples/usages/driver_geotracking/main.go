@@ -4,7 +4,6 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"os"
 
 	"github.com/saantiaguilera/go-pipeline"
 )
@@ -157,11 +156,10 @@ func RunGraphRendering() {
 		renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
 			Type: pipeline.UMLFormatSVG,
 		})
-		file, _ := os.Create("template.svg")
 
 		NewGraph().Draw(diagram)
 
-		if err := renderer.Render(diagram, file); err != nil {
+		if err := pipeline.RenderToFile(context.Background(), renderer, diagram, "template.svg"); err != nil {
 			panic(err)
 		}
 	}
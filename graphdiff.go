@@ -0,0 +1,407 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// GraphChangeAdded is an activity (or whole decision / concurrency) only present in the new graph
+	GraphChangeAdded GraphChangeKind = "added"
+	// GraphChangeRemoved is an activity (or whole decision / concurrency) only present in the old graph
+	GraphChangeRemoved GraphChangeKind = "removed"
+	// GraphChangeMoved is an activity present in both graphs, but at a different position of its sequence
+	GraphChangeMoved GraphChangeKind = "moved"
+	// GraphChangeCondition is a decision whose condition changed
+	GraphChangeCondition GraphChangeKind = "condition"
+
+	// Colors used for highlighting the changes of a diff
+	diffColorAdded   = "#palegreen"
+	diffColorRemoved = "#tomato"
+	diffColorMoved   = "#gold"
+)
+
+const (
+	diffActivity diffNodeKind = iota
+	diffDecision
+	diffFork
+)
+
+type (
+	// GraphChangeKind is the kind of a GraphChange
+	GraphChangeKind string
+
+	// GraphChange is a single structural change between two graphs
+	GraphChange struct {
+		Kind GraphChangeKind
+		// Path of the change, made of the decisions and concurrencies enclosing it.
+		// Eg. ["if is_close", "yes"] or ["fork", "branch 2"]
+		Path []string
+		// Label of the changed activity, or of the decision (eg. "if is_close") or concurrency ("fork")
+		Label string
+		// Before is the previous label, for condition changes
+		Before string
+	}
+
+	// GraphDiff is the structural difference between the graphs of two steps (see DiffGraphs).
+	//
+	// It's drawable, drawing the new graph along with the removed activities. Additions are colored green,
+	// removals red and moved activities yellow, while decisions whose condition changed are labeled with both.
+	//
+	//   diff := pipeline.DiffGraphs(oldStep, newStep)
+	//   fmt.Print(diff) // text report
+	//
+	//   graph := pipeline.NewUMLGraph()
+	//   diff.Draw(graph)
+	GraphDiff struct {
+		// Changes found, in drawing order
+		Changes []GraphChange
+
+		entries []diffEntry
+	}
+
+	diffNodeKind int
+
+	// diffNode is a drawn element of a graph. Decisions have a "yes" and a "no" branch, forks one per branch
+	diffNode struct {
+		kind     diffNodeKind
+		label    string
+		branches [][]*diffNode
+	}
+
+	// diffEntry is an element of the merged graph, along with how it changed
+	diffEntry struct {
+		change GraphChangeKind // empty if unchanged
+		node   *diffNode       // the new node, or the old one if removed
+		before string          // the old label, for condition changes
+		// branches of matched decisions and forks, merged
+		branches [][]diffEntry
+	}
+
+	// diffTreeGraph draws graphs as trees of diffNode
+	diffTreeGraph struct {
+		current *[]*diffNode
+	}
+)
+
+// DiffGraphs compares the graphs drawn by two steps (eg. two versions of a pipeline), reporting the
+// activities added and removed, the decisions whose condition changed and the activities reordered.
+func DiffGraphs(before, after DrawableGraph) *GraphDiff {
+	d := &GraphDiff{
+		entries: diffSequences(drawDiffTree(before), drawDiffTree(after)),
+	}
+	d.collect(d.entries, nil)
+	return d
+}
+
+// String returns the text report of the diff, a line per change. Changed conditions are prefixed with "~",
+// moved activities with ">", additions with "+" and removals with "-". Eg.
+//
+//	~ if is_far (was if is_close)
+//	+ notify_driver [if is_far/yes]
+//	- get_tracking [fork/branch 2]
+//	> get_driver
+func (d *GraphDiff) String() string {
+	if len(d.Changes) == 0 {
+		return "no changes\n"
+	}
+
+	var sb strings.Builder
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case GraphChangeAdded:
+			sb.WriteString("+ ")
+		case GraphChangeRemoved:
+			sb.WriteString("- ")
+		case GraphChangeMoved:
+			sb.WriteString("> ")
+		case GraphChangeCondition:
+			sb.WriteString("~ ")
+		}
+		sb.WriteString(c.Label)
+		if c.Kind == GraphChangeCondition {
+			sb.WriteString(fmt.Sprintf(" (was %s)", c.Before))
+		}
+		if len(c.Path) > 0 {
+			sb.WriteString(fmt.Sprintf(" [%s]", strings.Join(c.Path, "/")))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Draw the merged graph, highlighting its changes
+func (d *GraphDiff) Draw(graph Graph) {
+	drawDiffEntries(graph, d.entries, "")
+}
+
+// collect the changes of the entries, found at the given path
+func (d *GraphDiff) collect(entries []diffEntry, path []string) {
+	for _, e := range entries {
+		label := e.node.describe()
+		if len(e.change) > 0 {
+			d.Changes = append(d.Changes, GraphChange{
+				Kind:   e.change,
+				Path:   path,
+				Label:  label,
+				Before: e.before,
+			})
+		}
+		if e.change == GraphChangeAdded || e.change == GraphChangeRemoved {
+			continue // the whole subtree changed, it's reported once
+		}
+
+		for i, b := range e.branches {
+			branchPath := make([]string, len(path), len(path)+2)
+			copy(branchPath, path)
+			d.collect(b, append(branchPath, label, e.node.branchName(i)))
+		}
+	}
+}
+
+// describe the node as shown in reports
+func (n *diffNode) describe() string {
+	switch n.kind {
+	case diffDecision:
+		return fmt.Sprintf("if %s", n.label)
+	case diffFork:
+		return "fork"
+	default:
+		return n.label
+	}
+}
+
+func (n *diffNode) branchName(i int) string {
+	if n.kind == diffDecision {
+		if i == 0 {
+			return "yes"
+		}
+		return "no"
+	}
+	return fmt.Sprintf("branch %d", i+1)
+}
+
+// key identifying the node when matching sequences. Decisions and forks are matched regardless of their contents
+func (n *diffNode) key() string {
+	return fmt.Sprintf("%d:%s", n.kind, n.label)
+}
+
+func drawDiffTree(step DrawableGraph) []*diffNode {
+	var nodes []*diffNode
+	if step != nil {
+		step.Draw(&diffTreeGraph{current: &nodes})
+	}
+	return nodes
+}
+
+func (g *diffTreeGraph) AddConcurrency(branches ...GraphDrawer) {
+	if len(branches) == 0 {
+		return
+	}
+	g.add(diffFork, "", branches)
+}
+
+func (g *diffTreeGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	g.add(diffDecision, statement, []GraphDrawer{yes, no})
+}
+
+func (g *diffTreeGraph) AddActivity(label string) {
+	g.add(diffActivity, label, nil)
+}
+
+func (g *diffTreeGraph) add(kind diffNodeKind, label string, branches []GraphDrawer) {
+	n := &diffNode{kind: kind, label: label}
+	*g.current = append(*g.current, n)
+
+	prev := g.current
+	for _, b := range branches {
+		var nodes []*diffNode
+		g.current = &nodes
+		b(g)
+		n.branches = append(n.branches, nodes)
+	}
+	g.current = prev
+}
+
+// diffSequences merges two sequences of nodes, matching them through their longest common subsequence
+func diffSequences(before, after []*diffNode) []diffEntry {
+	// lcs[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i].key() == after[j].key() {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var entries []diffEntry
+	var removed, added []*diffNode // unmatched nodes since the last match
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i].key() == after[j].key():
+			entries = append(entries, diffGap(removed, added)...)
+			removed, added = nil, nil
+			entries = append(entries, diffMatch(before[i], after[j], ""))
+			i++
+			j++
+		case j < len(after) && (i == len(before) || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, after[j])
+			j++
+		default:
+			removed = append(removed, before[i])
+			i++
+		}
+	}
+	entries = append(entries, diffGap(removed, added)...)
+
+	return diffMoves(entries)
+}
+
+// diffMatch merges two matching nodes. If the condition of a decision changed, the old one is given
+func diffMatch(before, after *diffNode, oldCondition string) diffEntry {
+	e := diffEntry{node: after}
+	if len(oldCondition) > 0 {
+		e.change = GraphChangeCondition
+		e.before = fmt.Sprintf("if %s", oldCondition)
+	}
+
+	for k := 0; k < len(before.branches) || k < len(after.branches); k++ {
+		var b, a []*diffNode
+		if k < len(before.branches) {
+			b = before.branches[k]
+		}
+		if k < len(after.branches) {
+			a = after.branches[k]
+		}
+		e.branches = append(e.branches, diffSequences(b, a))
+	}
+	return e
+}
+
+// diffGap merges the unmatched nodes between two matches. Decisions removed and added in the same gap are
+// considered the same one, whose condition changed
+func diffGap(removed, added []*diffNode) []diffEntry {
+	var entries []diffEntry
+	for _, r := range removed {
+		entries = append(entries, diffEntry{change: GraphChangeRemoved, node: r})
+	}
+	for _, a := range added {
+		if r := firstRemovedDecision(entries); a.kind == diffDecision && r >= 0 {
+			before := entries[r].node
+			entries[r] = diffMatch(before, a, before.label)
+			continue
+		}
+		entries = append(entries, diffEntry{change: GraphChangeAdded, node: a})
+	}
+	return entries
+}
+
+// firstRemovedDecision returns the index of the first removed decision of the entries, or -1 if there's none
+func firstRemovedDecision(entries []diffEntry) int {
+	for i, e := range entries {
+		if e.change == GraphChangeRemoved && e.node.kind == diffDecision {
+			return i
+		}
+	}
+	return -1
+}
+
+// diffMoves marks as moved the activities of a sequence both removed and added. The added entry is kept at its
+// new position while the removed one is dropped
+func diffMoves(entries []diffEntry) []diffEntry {
+	removed := make(map[string]int)
+	for _, e := range entries {
+		if e.change == GraphChangeRemoved && e.node.kind == diffActivity {
+			removed[e.node.key()]++
+		}
+	}
+
+	moved := make(map[string]int)
+	for i, e := range entries {
+		if e.change == GraphChangeAdded && e.node.kind == diffActivity && removed[e.node.key()] > 0 {
+			removed[e.node.key()]--
+			moved[e.node.key()]++
+			entries[i].change = GraphChangeMoved
+		}
+	}
+
+	res := make([]diffEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.change == GraphChangeRemoved && e.node.kind == diffActivity && moved[e.node.key()] > 0 {
+			moved[e.node.key()]--
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// drawDiffEntries into the graph. Entries of a subtree that changed as a whole inherit its change
+func drawDiffEntries(graph Graph, entries []diffEntry, inherited GraphChangeKind) {
+	for _, e := range entries {
+		change := e.change
+		if len(inherited) > 0 {
+			change = inherited
+		}
+
+		switch e.node.kind {
+		case diffActivity:
+			drawDiffActivity(graph, e.node.label, change)
+		case diffDecision:
+			label := e.node.label
+			if change == GraphChangeCondition {
+				label = fmt.Sprintf("%s (was %s)", e.node.label, strings.TrimPrefix(e.before, "if "))
+			}
+			graph.AddDecision(label, diffBranchDrawer(e, 0, change), diffBranchDrawer(e, 1, change))
+		case diffFork:
+			// matched forks have a merged branch per branch of either of them, so removed branches are drawn too
+			count := max(len(e.node.branches), len(e.branches))
+			branches := make([]GraphDrawer, 0, count)
+			for i := 0; i < count; i++ {
+				branches = append(branches, diffBranchDrawer(e, i, change))
+			}
+			graph.AddConcurrency(branches...)
+		}
+	}
+}
+
+// diffBranchDrawer draws the i-th branch of the entry. Subtrees that changed as a whole are drawn from their nodes
+func diffBranchDrawer(e diffEntry, i int, change GraphChangeKind) GraphDrawer {
+	return func(graph Graph) {
+		if change == GraphChangeAdded || change == GraphChangeRemoved {
+			drawDiffEntries(graph, diffEntriesOf(e.node.branches[i]), change)
+			return
+		}
+		if i < len(e.branches) {
+			drawDiffEntries(graph, e.branches[i], "")
+		}
+	}
+}
+
+func diffEntriesOf(nodes []*diffNode) []diffEntry {
+	entries := make([]diffEntry, len(nodes))
+	for i, n := range nodes {
+		entries[i] = diffEntry{node: n}
+	}
+	return entries
+}
+
+func drawDiffActivity(graph Graph, label string, change GraphChangeKind) {
+	switch change {
+	case GraphChangeAdded:
+		DrawStyledActivity(graph, label, ActivityStyle{Color: diffColorAdded, Stereotypes: []string{"added"}})
+	case GraphChangeRemoved:
+		DrawStyledActivity(graph, label, ActivityStyle{Color: diffColorRemoved, Stereotypes: []string{"removed"}})
+	case GraphChangeMoved:
+		DrawStyledActivity(graph, label, ActivityStyle{Color: diffColorMoved, Stereotypes: []string{"moved"}})
+	default:
+		graph.AddActivity(label)
+	}
+}
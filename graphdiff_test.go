@@ -0,0 +1,145 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func newDiffedUnit(name string) pipeline.Step[int, int] {
+	return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+}
+
+func newDiffedSequence(steps ...pipeline.Step[int, int]) pipeline.Step[int, int] {
+	res := steps[0]
+	for _, s := range steps[1:] {
+		res = pipeline.NewSequentialStep(res, s)
+	}
+	return res
+}
+
+func newDiffedStatement(name string) pipeline.Statement[int] {
+	return pipeline.NewStatement(name, func(ctx context.Context, i int) bool {
+		return true
+	})
+}
+
+func TestDiffGraphs_GivenTheSameGraph_ThenThereAreNoChanges(t *testing.T) {
+	step := newDiffedSequence(newDiffedUnit("a"), newDiffedUnit("b"))
+
+	diff := pipeline.DiffGraphs(step, step)
+
+	assert.Empty(t, diff.Changes)
+	assert.Equal(t, "no changes\n", diff.String())
+}
+
+func TestDiffGraphs_GivenAddedAndRemovedActivities_ThenTheyAreReported(t *testing.T) {
+	before := newDiffedSequence(newDiffedUnit("a"), newDiffedUnit("b"), newDiffedUnit("c"))
+	after := newDiffedSequence(newDiffedUnit("a"), newDiffedUnit("c"), newDiffedUnit("d"))
+
+	diff := pipeline.DiffGraphs(before, after)
+
+	assert.Equal(t, []pipeline.GraphChange{
+		{Kind: pipeline.GraphChangeRemoved, Label: "b"},
+		{Kind: pipeline.GraphChangeAdded, Label: "d"},
+	}, diff.Changes)
+	assert.Equal(t, "- b\n+ d\n", diff.String())
+}
+
+func TestDiffGraphs_GivenAReorderedSequence_ThenMovedActivitiesAreReported(t *testing.T) {
+	before := newDiffedSequence(newDiffedUnit("a"), newDiffedUnit("b"), newDiffedUnit("c"))
+	after := newDiffedSequence(newDiffedUnit("b"), newDiffedUnit("a"), newDiffedUnit("c"))
+
+	diff := pipeline.DiffGraphs(before, after)
+
+	assert.Len(t, diff.Changes, 1)
+	assert.Equal(t, pipeline.GraphChangeMoved, diff.Changes[0].Kind)
+	assert.Contains(t, []string{"a", "b"}, diff.Changes[0].Label)
+}
+
+func TestDiffGraphs_GivenAChangedCondition_ThenItIsReportedAndItsBranchesDiffed(t *testing.T) {
+	before := pipeline.NewConditionalStep(newDiffedStatement("is_close"), newDiffedUnit("a"), newDiffedUnit("b"))
+	after := pipeline.NewConditionalStep(newDiffedStatement("is_far"), newDiffedUnit("a"), newDiffedUnit("c"))
+
+	diff := pipeline.DiffGraphs(before, after)
+
+	assert.Equal(t, []pipeline.GraphChange{
+		{Kind: pipeline.GraphChangeCondition, Label: "if is_far", Before: "if is_close"},
+		{Kind: pipeline.GraphChangeRemoved, Path: []string{"if is_far", "no"}, Label: "b"},
+		{Kind: pipeline.GraphChangeAdded, Path: []string{"if is_far", "no"}, Label: "c"},
+	}, diff.Changes)
+	assert.Equal(t, "~ if is_far (was if is_close)\n- b [if is_far/no]\n+ c [if is_far/no]\n", diff.String())
+}
+
+func TestDiffGraphs_GivenChangesInsideConcurrency_ThenTheyAreReportedWithTheirBranch(t *testing.T) {
+	sum := func(ctx context.Context, a, b int) (int, error) { return a + b, nil }
+	before := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{newDiffedUnit("a"), newDiffedUnit("b")}, sum)
+	after := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{
+		newDiffedUnit("a"),
+		newDiffedSequence(newDiffedUnit("b"), newDiffedUnit("c")),
+		newDiffedUnit("d"),
+	}, sum)
+
+	diff := pipeline.DiffGraphs(before, after)
+
+	assert.Equal(t, "+ c [fork/branch 2]\n+ d [fork/branch 3]\n", diff.String())
+}
+
+func TestDiffGraphs_GivenAnAddedDecision_ThenItIsReportedOnce(t *testing.T) {
+	before := newDiffedUnit("a")
+	after := newDiffedSequence(
+		newDiffedUnit("a"),
+		pipeline.NewOptionalStep[int](newDiffedStatement("is_even"), newDiffedUnit("b")),
+	)
+
+	diff := pipeline.DiffGraphs(before, after)
+
+	assert.Equal(t, "+ if is_even\n", diff.String())
+}
+
+func TestDiffGraphs_GivenChanges_WhenDrawnInUML_ThenAdditionsAreGreenAndRemovalsRed(t *testing.T) {
+	before := newDiffedSequence(
+		newDiffedUnit("a"),
+		pipeline.NewConditionalStep(newDiffedStatement("is_close"), newDiffedUnit("b"), newDiffedUnit("c")),
+	)
+	after := newDiffedSequence(
+		pipeline.NewConditionalStep(newDiffedStatement("is_far"), newDiffedUnit("b"), newDiffedUnit("d")),
+		pipeline.NewOptionalStep[int](newDiffedStatement("is_even"), newDiffedUnit("e")),
+	)
+	graph := pipeline.NewUMLGraph()
+
+	pipeline.DiffGraphs(before, after).Draw(graph)
+
+	expected := "start\n" +
+		"#tomato:a; <<removed>>\n" +
+		"if (is_far <U+0028>was is_close<U+0029>) then (yes)\n:b;\nelse (no)\n" +
+		"#tomato:c; <<removed>>\n#palegreen:d; <<added>>\nendif\n" +
+		"if (is_even) then (yes)\n#palegreen:e; <<added>>\nelse (no)\nendif\n" +
+		"stop\n"
+	assert.Contains(t, graph.String(), expected)
+}
+
+func TestDiffGraphs_GivenARemovedConcurrentBranch_WhenDrawnInUML_ThenItIsDrawnAsRemoved(t *testing.T) {
+	sum := func(ctx context.Context, a, b int) (int, error) { return a + b, nil }
+	before := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{
+		newDiffedUnit("a"),
+		newDiffedUnit("b"),
+		newDiffedUnit("c"),
+	}, sum)
+	after := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{newDiffedUnit("a"), newDiffedUnit("b")}, sum)
+	graph := pipeline.NewUMLGraph()
+
+	diff := pipeline.DiffGraphs(before, after)
+	diff.Draw(graph)
+
+	assert.Equal(t, "- c [fork/branch 3]\n", diff.String())
+	expected := "start\n" +
+		"fork\n:a;\nfork again\n:b;\nfork again\n#tomato:c; <<removed>>\nend fork\n" +
+		"stop\n"
+	assert.Contains(t, graph.String(), expected)
+}
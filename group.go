@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+)
+
+type (
+	// GroupStep wraps a step (usually a sub-pipeline) under a name.
+	//
+	// It's drawn as a partition grouping what the step draws, or collapsed into a single activity (optionally
+	// linking to a separate rendering of the step). When run, it scopes the step: errors get the group in their
+	// path and interceptors implementing GroupInterceptor (eg. the tracing one) are notified of it.
+	GroupStep[I, O any] struct {
+		name      string
+		step      Step[I, O]
+		collapsed bool
+		link      string
+	}
+)
+
+// NewGroupStep creates a step that groups the given one under a name
+func NewGroupStep[I, O any](name string, step Step[I, O]) GroupStep[I, O] {
+	return GroupStep[I, O]{
+		name: name,
+		step: step,
+	}
+}
+
+// Name of the group
+func (g GroupStep[I, O]) Name() string {
+	return g.name
+}
+
+// Collapse returns a copy of this group that is drawn as a single activity linking to the given URL
+// (eg. a separate rendering of the grouped step). The link may be empty
+func (g GroupStep[I, O]) Collapse(link string) GroupStep[I, O] {
+	g.collapsed = true
+	g.link = link
+	return g
+}
+
+// Draw the grouped step inside a partition, or a single activity if collapsed
+func (g GroupStep[I, O]) Draw(graph Graph) {
+	if g.collapsed {
+		DrawDescribedActivity(graph, g.name, ActivityStyle{Stereotypes: []string{"group"}}, StepMetadata{Link: g.link})
+		return
+	}
+	DrawPartition(graph, g.name, g.step.Draw)
+}
+
+// Kind of this step, see StepKindGroup
+func (g GroupStep[I, O]) Kind() StepKind {
+	return StepKindGroup
+}
+
+// Children of this step, the grouped one
+func (g GroupStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{g.step}
+}
+
+func (g GroupStep[I, O]) problems() []string {
+	if g.step == nil {
+		return []string{"nil grouped step"}
+	}
+	return nil
+}
+
+// Run the grouped step within the scope of this group
+func (g GroupStep[I, O]) Run(ctx context.Context, in I) (O, error) {
+	res, err := interceptGroup(ctx, g.name, func(ctx context.Context) (O, error) {
+		return g.step.Run(withStepPath(ctx, g, 0), in)
+	})
+	return res, prependStepPath(err, g, 0)
+}
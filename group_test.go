@@ -0,0 +1,112 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestGroupStep_GivenAStep_WhenDrawn_ThenItIsDrawnInsideAPartition(t *testing.T) {
+	graph := pipeline.NewUMLGraph()
+	step := pipeline.NewGroupStep[int, int]("group", pipeline.NewSequentialStep[int, int, int](
+		newIntrospectedUnit("a"),
+		newIntrospectedUnit("b"),
+	))
+
+	step.Draw(graph)
+
+	assert.Contains(t, graph.String(), "\npartition \"group\" {\n:a; <<unit>>\n:b; <<unit>>\n}\n")
+}
+
+func TestGroupStep_GivenAGraphWithoutPartitions_WhenDrawn_ThenTheStepIsDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	inner := new(mockStep[int, int])
+	inner.On("Draw", graph).Once()
+
+	pipeline.NewGroupStep[int, int]("group", inner).Draw(graph)
+
+	inner.AssertExpectations(t)
+	graph.AssertExpectations(t)
+}
+
+func TestGroupStep_GivenACollapsedGroup_WhenDrawn_ThenASingleLinkedActivityIsDrawn(t *testing.T) {
+	graph := pipeline.NewUMLGraph()
+	step := pipeline.NewGroupStep[int, int]("group", newIntrospectedUnit("a")).Collapse("http://diagrams/group.svg")
+
+	step.Draw(graph)
+
+	content := graph.String()
+	assert.Contains(t, content, "\n:group; <<group>>\nnote right\n[[http://diagrams/group.svg]]\nend note\n")
+	assert.NotContains(t, content, ":a;")
+}
+
+func TestGroupStep_GivenAFailingStep_WhenRun_ThenTheErrorPathHasTheGroup(t *testing.T) {
+	expectedErr := errors.New("some error")
+	step := pipeline.NewGroupStep[int, int]("group", pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return 0, expectedErr
+	}))
+
+	_, err := step.Run(context.Background(), 1)
+
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, "group:group/unit: some error", err.Error())
+}
+
+func TestGroupStep_GivenAStep_WhenRun_ThenItsResultIsReturned(t *testing.T) {
+	inner := new(mockStep[int, int])
+	inner.On("Run", mock.Anything, 1).Return(2, nil).Once()
+
+	res, err := pipeline.NewGroupStep[int, int]("group", inner).Run(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res)
+	inner.AssertExpectations(t)
+}
+
+func TestGroupStep_GivenATracingInterceptor_WhenRun_ThenTheGroupIsASpanParentOfItsUnits(t *testing.T) {
+	expectedErr := errors.New("some error")
+	tracer := pipeline.NewRecordingTracer()
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.NewTracingInterceptor(tracer))
+	step := pipeline.NewGroupStep[int, int]("group", pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return 0, expectedErr
+	}))
+
+	_, _ = step.Run(ctx, 1)
+
+	roots := tracer.Children("")
+	assert.Len(t, roots, 1)
+	assert.Equal(t, "group", roots[0].Name)
+	assert.ErrorIs(t, roots[0].Err, expectedErr)
+	children := tracer.Children(roots[0].ID)
+	assert.Len(t, children, 1)
+	assert.Equal(t, "unit", children[0].Name)
+}
+
+func TestGroupStep_GivenATracerKeepingItsOwnSpans_WhenRun_ThenTheGroupSpanEnds(t *testing.T) {
+	tracer := new(ownContextTracer)
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.NewTracingInterceptor(tracer))
+	step := pipeline.NewGroupStep[int, int]("group", newIntrospectedUnit("unit"))
+
+	_, _ = step.Run(ctx, 1)
+
+	assert.Equal(t, []string{"unit: <nil>", "group: <nil>"}, tracer.Ended())
+}
+
+func TestGroupStep_GivenAGroup_WhenIntrospected_ThenItIsAScope(t *testing.T) {
+	step := pipeline.NewGroupStep[int, int]("group", newIntrospectedUnit("a"))
+	var paths [][]string
+
+	_ = pipeline.Walk(step, func(_ pipeline.DrawableGraph, path []string) error {
+		paths = append(paths, path)
+		return nil
+	})
+
+	assert.Equal(t, pipeline.StepKindGroup, pipeline.KindOf(step))
+	assert.Equal(t, [][]string{{"group:group"}, {"group:group", "a"}}, paths)
+	assert.Equal(t, []string{"group:group: nil grouped step"}, validationMessages(t, pipeline.Validate(pipeline.NewGroupStep[int, int]("group", nil))))
+}
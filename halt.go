@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type (
+	// HaltBoundaryStep decorates a step, converting any halt signal of its output type raised
+	// inside it into a successful result.
+	//
+	// This allows a step deep inside a pipeline to short-circuit the remaining work (eg. because the
+	// event was already processed) without it being considered a failure.
+	HaltBoundaryStep[I, O any] struct {
+		step Step[I, O]
+	}
+
+	// haltSignal is the error used to short-circuit a pipeline with a given result.
+	haltSignal[T any] struct {
+		result T
+	}
+
+	// halter allows detecting halt signals regardless of the result type they carry.
+	halter interface {
+		halted()
+	}
+)
+
+// Halt creates a signal that stops the pipeline successfully with the given result.
+//
+// Steps should return it as their error. Since it's an error, enclosing steps (eg. a SequentialStep) will
+// stop running the next ones and forward it, until a HaltBoundaryStep with the same output type
+// converts it into a normal output.
+//
+//	return *new(O), pipeline.Halt(result)
+func Halt[T any](result T) error {
+	return haltSignal[T]{result: result}
+}
+
+// IsHalt reports whether the error is (or wraps) a halt signal, regardless of the result it carries.
+func IsHalt(err error) bool {
+	var h halter
+	return errors.As(err, &h)
+}
+
+// NewHaltBoundaryStep creates a step that will convert halt signals of type O raised by the inner step into
+// successful outputs. Other errors (and halt signals of different types) are returned as they are.
+func NewHaltBoundaryStep[I, O any](step Step[I, O]) HaltBoundaryStep[I, O] {
+	return HaltBoundaryStep[I, O]{
+		step: step,
+	}
+}
+
+// Draw the bounded step, stereotyping its activities as bounded
+func (h HaltBoundaryStep[I, O]) Draw(graph Graph) {
+	DrawStereotyped(graph, "halt_boundary", h.step.Draw)
+}
+
+// Kind of this step, see StepKindHaltBoundary
+func (h HaltBoundaryStep[I, O]) Kind() StepKind {
+	return StepKindHaltBoundary
+}
+
+// Children of this step, the bounded one
+func (h HaltBoundaryStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{h.step}
+}
+
+func (h HaltBoundaryStep[I, O]) problems() []string {
+	if h.step == nil {
+		return []string{"nil bounded step"}
+	}
+	return nil
+}
+
+// Run the inner step. If it halts with a result of type O, the result is returned without error.
+func (h HaltBoundaryStep[I, O]) Run(ctx context.Context, in I) (O, error) {
+	res, err := h.step.Run(withStepPath(ctx, h, 0), in)
+	if err == nil {
+		return res, nil
+	}
+
+	var sig haltSignal[O]
+	if errors.As(err, &sig) {
+		return sig.result, nil
+	}
+	return res, prependStepPath(err, h, 0)
+}
+
+func (h haltSignal[T]) Error() string {
+	return fmt.Sprintf("pipeline halted with result: %v", h.result)
+}
+
+func (h haltSignal[T]) halted() {}
@@ -0,0 +1,137 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+// The following example shows a step that short-circuits a sequence when it detects the
+// work is already done. The boundary converts the halt into a successful output and the
+// rest of the sequence is never run.
+//
+// This example uses dummy data to showcase as simple as possible this scenario.
+func ExampleHaltBoundaryStep() {
+	type Event int
+	type Receipt string
+	check := pipeline.NewUnitStep(
+		"check_already_processed",
+		func(ctx context.Context, e Event) (Event, error) {
+			if e == 1 {
+				return e, pipeline.Halt(Receipt("already processed"))
+			}
+			return e, nil
+		},
+	)
+	process := pipeline.NewUnitStep(
+		"process",
+		func(ctx context.Context, e Event) (Receipt, error) {
+			return Receipt("processed"), nil
+		},
+	)
+
+	pipe := pipeline.NewHaltBoundaryStep[Event, Receipt](
+		pipeline.NewSequentialStep[Event, Event, Receipt](check, process),
+	)
+
+	out, err := pipe.Run(context.Background(), Event(1))
+	fmt.Println(out, err)
+
+	out, err = pipe.Run(context.Background(), Event(2))
+	fmt.Println(out, err)
+
+	// output:
+	// already processed <nil>
+	// processed <nil>
+}
+
+func TestHaltBoundaryStep_GivenAHaltingStep_WhenRun_ThenHaltResultIsReturnedWithoutError(t *testing.T) {
+	inner := new(mockStep[int, string])
+	inner.On("Run", mock.Anything, 1).Return("", pipeline.Halt("done"))
+	step := pipeline.NewHaltBoundaryStep[int, string](inner)
+
+	v, err := step.Run(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "done", v)
+}
+
+func TestHaltBoundaryStep_GivenANestedHalt_WhenRun_ThenNextSequentialStepsAreNotRun(t *testing.T) {
+	start := new(mockStep[int, int])
+	start.On("Run", mock.Anything, 1).Return(0, pipeline.Halt("done"))
+	end := new(mockStep[int, string])
+	step := pipeline.NewHaltBoundaryStep[int, string](
+		pipeline.NewSequentialStep[int, int, string](start, end),
+	)
+
+	v, err := step.Run(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "done", v)
+	mock.AssertExpectationsForObjects(t, start, end)
+}
+
+func TestHaltBoundaryStep_GivenAWrappedHalt_WhenRun_ThenHaltResultIsReturned(t *testing.T) {
+	inner := new(mockStep[int, string])
+	inner.On("Run", mock.Anything, 1).Return("", fmt.Errorf("wrapped: %w", pipeline.Halt("done")))
+	step := pipeline.NewHaltBoundaryStep[int, string](inner)
+
+	v, err := step.Run(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "done", v)
+}
+
+func TestHaltBoundaryStep_GivenAHaltOfADifferentType_WhenRun_ThenHaltIsForwarded(t *testing.T) {
+	inner := new(mockStep[int, string])
+	inner.On("Run", mock.Anything, 1).Return("", pipeline.Halt(25))
+	step := pipeline.NewHaltBoundaryStep[int, string](inner)
+
+	_, err := step.Run(context.Background(), 1)
+
+	assert.NotNil(t, err)
+	assert.True(t, pipeline.IsHalt(err))
+}
+
+func TestHaltBoundaryStep_GivenAFailingStep_WhenRun_ThenErrorIsReturned(t *testing.T) {
+	expectedErr := errors.New("some error")
+	inner := new(mockStep[int, string])
+	inner.On("Run", mock.Anything, 1).Return("", expectedErr)
+	step := pipeline.NewHaltBoundaryStep[int, string](inner)
+
+	_, err := step.Run(context.Background(), 1)
+
+	assert.Equal(t, expectedErr, err)
+	assert.False(t, pipeline.IsHalt(err))
+}
+
+func TestHaltBoundaryStep_GivenAStepToDraw_WhenDrawn_ThenDelegatesToInnerStep(t *testing.T) {
+	mockGraph := new(mockGraph)
+	inner := new(mockStep[int, string])
+	inner.On("Draw", mockGraph).Once()
+	step := pipeline.NewHaltBoundaryStep[int, string](inner)
+
+	step.Draw(mockGraph)
+
+	inner.AssertExpectations(t)
+}
+
+func TestHalt_GivenATracedHaltingStep_WhenRun_ThenNoFailureIsLogged(t *testing.T) {
+	inner := new(mockStep[any, any])
+	inner.On("Run", mock.Anything, 1).Return(nil, pipeline.Halt(1))
+	writer := bytes.NewBufferString("")
+	step := pipeline.NewTracedStepWithWriter[any, any]("test name", inner, writer)
+	validator := regexp.MustCompile(`^\[STAGE] .* \| test name \| [.\d]+[µnm]s \| Halted\n$`)
+
+	_, _ = step.Run(context.Background(), 1)
+
+	assert.True(t, validator.Match(writer.Bytes()))
+}
@@ -0,0 +1,248 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Interceptor hooks into every UnitStep execution run with a context carrying it (see WithInterceptors).
+	//
+	// Since interceptors travel through the context, they reach every unit of a pipeline regardless of how
+	// deep it's nested inside other steps (sequential, concurrent, conditional, optional or custom ones).
+	//
+	// Implementations must be safe for concurrent use, as steps may run concurrently.
+	Interceptor interface {
+		// Before is called right before running the unit. The returned context is the one used for running
+		// the unit and for calling After, so it can be used to carry state between both hooks.
+		Before(ctx context.Context, call StepCall) context.Context
+		// After is called once the unit finished, with its output and error populated in the call.
+		After(ctx context.Context, call StepCall)
+	}
+
+	// DecisionInterceptor is an optional interface an Interceptor can implement for being notified of the
+	// statements evaluated by ConditionalStep and OptionalStep, and which branch they took.
+	DecisionInterceptor interface {
+		// Decided is called once a statement was evaluated, with the result of its evaluation
+		Decided(ctx context.Context, statement string, result bool)
+	}
+
+	// GroupInterceptor is an optional interface an Interceptor can implement for being notified of the groups
+	// (see GroupStep) run, which act as scopes of the units they contain.
+	GroupInterceptor interface {
+		// BeforeGroup is called right before running the group. The returned context is the one used for running
+		// the group and for calling AfterGroup.
+		BeforeGroup(ctx context.Context, name string) context.Context
+		// AfterGroup is called once the group finished, with the error it failed with (if any)
+		AfterGroup(ctx context.Context, name string, err error)
+	}
+
+	// ForkInterceptor is an optional interface an Interceptor can implement for being notified of the branches
+	// a ConcurrentStep runs in new goroutines.
+	ForkInterceptor interface {
+		// Fork is called right before spawning the goroutine of a branch. The returned context is the one used
+		// for running the branch.
+		Fork(ctx context.Context) context.Context
+	}
+
+	// StepCall describes a single execution of a UnitStep.
+	StepCall struct {
+		// ID of the step, see UnitStep.ID
+		ID string
+		// Name of the step, see UnitStep.Name
+		Name string
+		// Metadata of the step, see UnitStep.Metadata
+		Metadata StepMetadata
+		// Input the step was run with
+		Input any
+		// Output the step yielded. Only populated in Interceptor.After
+		Output any
+		// Err the step failed with, if any. Only populated in Interceptor.After
+		Err error
+	}
+
+	// InterceptorFuncs is an Interceptor built from functions. Nil functions are skipped.
+	InterceptorFuncs struct {
+		BeforeFunc func(context.Context, StepCall) context.Context
+		AfterFunc  func(context.Context, StepCall)
+	}
+
+	tracingInterceptor struct {
+		tracer Tracer
+	}
+
+	metricsInterceptor struct {
+		collector MetricsCollector
+	}
+
+	interceptorsContextKey struct{}
+	metricsStartContextKey struct{}
+	// tracingSpanContextKey carries the span the tracing interceptor started, as tracers may keep their spans
+	// in their own context keys instead of through ContextWithSpan
+	tracingSpanContextKey struct{}
+)
+
+// WithInterceptors returns a copy of the context carrying the given interceptors (along with the ones the context
+// already had). Every UnitStep run with it will call them in order before running, and in reverse order after.
+func WithInterceptors(ctx context.Context, interceptors ...Interceptor) context.Context {
+	prev := interceptorsFromContext(ctx)
+	all := make([]Interceptor, 0, len(prev)+len(interceptors))
+	all = append(all, prev...)
+	all = append(all, interceptors...)
+	return context.WithValue(ctx, interceptorsContextKey{}, all)
+}
+
+// NewTracingInterceptor creates an interceptor that starts a span of the tracer for every unit
+func NewTracingInterceptor(tracer Tracer) Interceptor {
+	return tracingInterceptor{
+		tracer: tracer,
+	}
+}
+
+// NewMetricsInterceptor creates an interceptor that reports every unit execution to the collector
+func NewMetricsInterceptor(collector MetricsCollector) Interceptor {
+	return metricsInterceptor{
+		collector: collector,
+	}
+}
+
+func interceptorsFromContext(ctx context.Context) []Interceptor {
+	interceptors, _ := ctx.Value(interceptorsContextKey{}).([]Interceptor)
+	return interceptors
+}
+
+// interceptDecision notifies the interceptors the context carries about a statement evaluation
+func interceptDecision(ctx context.Context, statement interface{ Name() string }, result bool) {
+	for _, ic := range interceptorsFromContext(ctx) {
+		if d, ok := ic.(DecisionInterceptor); ok {
+			d.Decided(ctx, statement.Name(), result)
+		}
+	}
+}
+
+// interceptFork notifies the fork interceptors the context carries about a branch about to run in a new goroutine,
+// returning the context for running it
+func interceptFork(ctx context.Context) context.Context {
+	for _, ic := range interceptorsFromContext(ctx) {
+		if f, ok := ic.(ForkInterceptor); ok {
+			ctx = f.Fork(ctx)
+		}
+	}
+	return ctx
+}
+
+// intercept runs the unit surrounded by the given interceptors
+func intercept[I, O any](ctx context.Context, interceptors []Interceptor, call StepCall, fn Unit[I, O], in I) (O, error) {
+	ctxs := make([]context.Context, len(interceptors)) // context each interceptor yielded, for its After call
+	for i, ic := range interceptors {
+		ctx = ic.Before(ctx, call)
+		ctxs[i] = ctx
+	}
+
+	res, err := fn(ctx, in)
+
+	call.Output = res
+	call.Err = err
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptors[i].After(ctxs[i], call)
+	}
+	return res, err
+}
+
+// interceptGroup runs the group surrounded by the group interceptors the context carries
+func interceptGroup[O any](ctx context.Context, name string, run func(context.Context) (O, error)) (O, error) {
+	var groups []GroupInterceptor
+	for _, ic := range interceptorsFromContext(ctx) {
+		if g, ok := ic.(GroupInterceptor); ok {
+			groups = append(groups, g)
+		}
+	}
+
+	ctxs := make([]context.Context, len(groups)) // context each interceptor yielded, for its AfterGroup call
+	for i, g := range groups {
+		ctx = g.BeforeGroup(ctx, name)
+		ctxs[i] = ctx
+	}
+
+	res, err := run(ctx)
+
+	for i := len(groups) - 1; i >= 0; i-- {
+		groups[i].AfterGroup(ctxs[i], name, err)
+	}
+	return res, err
+}
+
+func (f InterceptorFuncs) Before(ctx context.Context, call StepCall) context.Context {
+	if f.BeforeFunc == nil {
+		return ctx
+	}
+	return f.BeforeFunc(ctx, call)
+}
+
+func (f InterceptorFuncs) After(ctx context.Context, call StepCall) {
+	if f.AfterFunc != nil {
+		f.AfterFunc(ctx, call)
+	}
+}
+
+func (t tracingInterceptor) Before(ctx context.Context, call StepCall) context.Context {
+	ctx, span := t.tracer.Start(ctx, call.Name)
+	if attrs := call.Metadata.Attributes(); len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	return context.WithValue(ctx, tracingSpanContextKey{}, span)
+}
+
+func (t tracingInterceptor) After(ctx context.Context, call StepCall) {
+	t.end(ctx, call.Err)
+}
+
+func (t tracingInterceptor) BeforeGroup(ctx context.Context, name string) context.Context {
+	ctx, span := t.tracer.Start(ctx, name)
+	return context.WithValue(ctx, tracingSpanContextKey{}, span)
+}
+
+func (t tracingInterceptor) AfterGroup(ctx context.Context, _ string, err error) {
+	t.end(ctx, err)
+}
+
+// end the span started by Before (or BeforeGroup), which the context carries
+func (t tracingInterceptor) end(ctx context.Context, err error) {
+	span, ok := ctx.Value(tracingSpanContextKey{}).(Span)
+	if !ok {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+func (m metricsInterceptor) Before(ctx context.Context, call StepCall) context.Context {
+	if c, labels, ok := m.labeled(call); ok {
+		c.StepStartedWithLabels(call.Name, labels)
+	} else {
+		m.collector.StepStarted(call.Name)
+	}
+	return context.WithValue(ctx, metricsStartContextKey{}, time.Now())
+}
+
+func (m metricsInterceptor) After(ctx context.Context, call StepCall) {
+	start, _ := ctx.Value(metricsStartContextKey{}).(time.Time)
+	if c, labels, ok := m.labeled(call); ok {
+		c.StepFinishedWithLabels(call.Name, labels, time.Since(start), call.Err)
+	} else {
+		m.collector.StepFinished(call.Name, time.Since(start), call.Err)
+	}
+}
+
+// labeled returns the collector as a LabeledMetricsCollector along with the labels of the call, if it supports
+// them and the call has any
+func (m metricsInterceptor) labeled(call StepCall) (LabeledMetricsCollector, map[string]string, bool) {
+	c, ok := m.collector.(LabeledMetricsCollector)
+	if !ok {
+		return nil, nil, false
+	}
+	labels := call.Metadata.Labels()
+	return c, labels, len(labels) > 0
+}
@@ -0,0 +1,272 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+type ctxKey string
+
+// The following example shows how to hook into every unit of a pipeline, no matter
+// how deep it's nested, by adding an interceptor to the context the pipeline runs with.
+func ExampleWithInterceptors() {
+	inc := pipeline.NewUnitStep("increase", func(ctx context.Context, i int) (int, error) {
+		return i + 1, nil
+	})
+	double := pipeline.NewUnitStep("double", func(ctx context.Context, i int) (int, error) {
+		return i * 2, nil
+	})
+	pipe := pipeline.NewSequentialStep[int, int, int](inc, double)
+
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.InterceptorFuncs{
+		AfterFunc: func(ctx context.Context, call pipeline.StepCall) {
+			fmt.Println(call.Name, call.Input, call.Output, call.Err)
+		},
+	})
+
+	out, err := pipe.Run(ctx, 1)
+	fmt.Println(out, err)
+
+	// output:
+	// increase 1 2 <nil>
+	// double 2 4 <nil>
+	// 4 <nil>
+}
+
+func TestWithInterceptors_GivenNestedUnits_WhenRun_ThenEveryUnitIsIntercepted(t *testing.T) {
+	var mux sync.Mutex
+	var names []string
+	unit := func(name string) pipeline.UnitStep[int, int] {
+		return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		})
+	}
+	stmt := pipeline.NewStatement("stmt", func(ctx context.Context, i int) bool {
+		return true
+	})
+	pipe := pipeline.NewSequentialStep[int, int, int](
+		pipeline.NewConcurrentStep([]pipeline.Step[int, int]{unit("a"), unit("b")}, func(ctx context.Context, a, b int) (int, error) {
+			return a, nil
+		}),
+		pipeline.NewSequentialStep[int, int, int](
+			pipeline.NewConditionalStep[int, int](stmt, unit("c"), unit("d")),
+			pipeline.NewOptionalStep[int](stmt, unit("e")),
+		),
+	)
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.InterceptorFuncs{
+		AfterFunc: func(ctx context.Context, call pipeline.StepCall) {
+			mux.Lock()
+			defer mux.Unlock()
+			names = append(names, call.Name)
+		},
+	})
+
+	_, err := pipe.Run(ctx, 1)
+
+	sort.Strings(names)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "e"}, names)
+}
+
+func TestWithInterceptors_GivenManyInterceptors_WhenRun_ThenTheyAreCalledAsAnOnion(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) pipeline.Interceptor {
+		return pipeline.InterceptorFuncs{
+			BeforeFunc: func(ctx context.Context, call pipeline.StepCall) context.Context {
+				calls = append(calls, "before "+name)
+				return context.WithValue(ctx, ctxKey(name), name)
+			},
+			AfterFunc: func(ctx context.Context, call pipeline.StepCall) {
+				calls = append(calls, fmt.Sprintf("after %s %v", name, ctx.Value(ctxKey(name))))
+			},
+		}
+	}
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		calls = append(calls, fmt.Sprintf("run %v %v", ctx.Value(ctxKey("1")), ctx.Value(ctxKey("2"))))
+		return i, nil
+	})
+	ctx := pipeline.WithInterceptors(context.Background(), interceptor("1"))
+	ctx = pipeline.WithInterceptors(ctx, interceptor("2"))
+
+	_, _ = step.Run(ctx, 1)
+
+	assert.Equal(t, []string{"before 1", "before 2", "run 1 2", "after 2 2", "after 1 1"}, calls)
+}
+
+func TestWithInterceptors_GivenAFailingUnit_WhenRun_ThenCallCarriesStepData(t *testing.T) {
+	expectedErr := errors.New("some error")
+	var before, after pipeline.StepCall
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return 2, expectedErr
+	})
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.InterceptorFuncs{
+		BeforeFunc: func(ctx context.Context, call pipeline.StepCall) context.Context {
+			before = call
+			return ctx
+		},
+		AfterFunc: func(ctx context.Context, call pipeline.StepCall) {
+			after = call
+		},
+	})
+
+	_, _ = step.Run(ctx, 1)
+
+	assert.Equal(t, pipeline.StepCall{ID: step.ID(), Name: "unit", Input: 1}, before)
+	assert.Equal(t, pipeline.StepCall{ID: step.ID(), Name: "unit", Input: 1, Output: 2, Err: expectedErr}, after)
+}
+
+func TestWithInterceptors_GivenEmptyInterceptorFuncs_WhenRun_ThenUnitRuns(t *testing.T) {
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i + 1, nil
+	})
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.InterceptorFuncs{})
+
+	v, err := step.Run(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestNewTracingInterceptor_GivenATracer_WhenUnitsRun_ThenSpansAreRecorded(t *testing.T) {
+	expectedErr := errors.New("some error")
+	tracer := pipeline.NewRecordingTracer()
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, expectedErr
+	})
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.NewTracingInterceptor(tracer))
+
+	_, _ = pipeline.NewTracedStepWithTracer[int, int]("root", step, tracer).Run(ctx, 1)
+
+	spans := tracer.Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "unit", spans[0].Name)
+	assert.Equal(t, expectedErr, spans[0].Err)
+	assert.Equal(t, spans[1].ID, spans[0].ParentID)
+}
+
+func TestNewTracingInterceptor_GivenATracerKeepingItsOwnSpans_WhenUnitsRun_ThenTheirSpansEnd(t *testing.T) {
+	expectedErr := errors.New("some error")
+	tracer := new(ownContextTracer)
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, expectedErr
+	})
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.NewTracingInterceptor(tracer))
+
+	_, _ = step.Run(ctx, 1)
+
+	assert.Equal(t, []string{"unit: some error"}, tracer.Ended())
+}
+
+func TestNewMetricsInterceptor_GivenACollector_WhenUnitsRun_ThenExecutionsAreReported(t *testing.T) {
+	collector := new(mockMetricsCollector)
+	collector.On("StepStarted", "unit").Once()
+	collector.On("StepFinished", "unit", mock.AnythingOfType("time.Duration"), nil).Once()
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.NewMetricsInterceptor(collector))
+
+	_, _ = step.Run(ctx, 1)
+
+	collector.AssertExpectations(t)
+}
+
+func TestNewMetricsInterceptor_GivenAUnitWithAnOwner_WhenRun_ThenItsSeriesIsLabeledWithIt(t *testing.T) {
+	collector := pipeline.NewMemoryMetricsCollector(pipeline.MetricsOptions{})
+	step := pipeline.NewSequentialStep[int, int, int](
+		pipeline.NewUnitStep("owned", func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		}).WithMetadata(pipeline.StepMetadata{Owner: "payments", Tags: []string{"critical"}}),
+		pipeline.NewUnitStep("unowned", func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		}),
+	)
+	ctx := pipeline.WithInterceptors(context.Background(), pipeline.NewMetricsInterceptor(collector))
+	var buf bytes.Buffer
+
+	_, _ = step.Run(ctx, 1)
+	_, _ = collector.WriteTo(&buf)
+
+	assert.Contains(t, buf.String(), "pipeline_step_calls_total{step=\"owned\",owner=\"payments\"} 1\n")
+	assert.Contains(t, buf.String(), "pipeline_step_calls_total{step=\"unowned\"} 1\n")
+	assert.Contains(t, buf.String(), "pipeline_step_duration_seconds_count{step=\"owned\",owner=\"payments\"} 1\n")
+}
+
+type forkInterceptor struct {
+	pipeline.InterceptorFuncs
+	forks *int32
+}
+
+func (f forkInterceptor) Fork(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey("fork"), atomic.AddInt32(f.forks, 1))
+}
+
+func TestWithInterceptors_GivenAForkInterceptor_WhenConcurrentBranchesRun_ThenEachOneIsForked(t *testing.T) {
+	var forks int32
+	var mux sync.Mutex
+	var seen []int32
+	unit := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		seen = append(seen, ctx.Value(ctxKey("fork")).(int32))
+		return i, nil
+	})
+	step := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{unit, unit}, func(ctx context.Context, a, b int) (int, error) {
+		return a, nil
+	})
+
+	_, err := step.Run(pipeline.WithInterceptors(context.Background(), forkInterceptor{forks: &forks}), 1)
+
+	sort.Slice(seen, func(i, j int) bool { return seen[i] < seen[j] })
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{1, 2}, seen)
+}
+
+// ownContextTracer is a tracer keeping its spans in its own context key (eg. as an adapter of a tracing library),
+// recording the spans ended
+type ownContextTracer struct {
+	mux   sync.Mutex
+	ended []string
+}
+
+type ownContextSpan struct {
+	tracer *ownContextTracer
+	name   string
+	err    error
+}
+
+type ownContextSpanKey struct{}
+
+func (t *ownContextTracer) Start(ctx context.Context, name string) (context.Context, pipeline.Span) {
+	s := &ownContextSpan{tracer: t, name: name}
+	return context.WithValue(ctx, ownContextSpanKey{}, s), s
+}
+
+func (t *ownContextTracer) Ended() []string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return append([]string(nil), t.ended...)
+}
+
+func (s *ownContextSpan) SetAttributes(...pipeline.Attribute) {}
+
+func (s *ownContextSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *ownContextSpan) End() {
+	s.tracer.mux.Lock()
+	defer s.tracer.mux.Unlock()
+	s.tracer.ended = append(s.tracer.ended, fmt.Sprintf("%s: %v", s.name, s.err))
+}
@@ -0,0 +1,253 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// StepKindUnit is the kind of a UnitStep
+	StepKindUnit StepKind = "unit"
+	// StepKindSequential is the kind of a SequentialStep
+	StepKindSequential StepKind = "sequential"
+	// StepKindConcurrent is the kind of a ConcurrentStep
+	StepKindConcurrent StepKind = "concurrent"
+	// StepKindConditional is the kind of a ConditionalStep
+	StepKindConditional StepKind = "conditional"
+	// StepKindOptional is the kind of an OptionalStep
+	StepKindOptional StepKind = "optional"
+	// StepKindTraced is the kind of a TracedStep
+	StepKindTraced StepKind = "traced"
+	// StepKindMetrics is the kind of a MetricsStep
+	StepKindMetrics StepKind = "metrics"
+	// StepKindHaltBoundary is the kind of a HaltBoundaryStep
+	StepKindHaltBoundary StepKind = "halt_boundary"
+	// StepKindGroup is the kind of a GroupStep
+	StepKindGroup StepKind = "group"
+	// StepKindCheckpoint is the kind of a CheckpointStep
+	StepKindCheckpoint StepKind = "checkpoint"
+	// StepKindCustom is the kind of the steps that can't be inspected
+	StepKindCustom StepKind = "custom"
+)
+
+var (
+	// SkipChildren can be returned by a StepVisitor for skipping the children of the visited step
+	SkipChildren = errors.New("skip children")
+
+	// errFound stops a walk once the step looked for is found
+	errFound = errors.New("found")
+)
+
+type (
+	// StepKind describes what a step is
+	StepKind string
+
+	// InspectableStep is an optional interface steps can implement to expose their structure.
+	// Every built-in step implements it, custom steps that don't are walked as leafs of kind StepKindCustom.
+	InspectableStep interface {
+		// Kind of the step
+		Kind() StepKind
+		// Children of the step, in the order they are drawn. Missing branches are nil
+		Children() []DrawableGraph
+	}
+
+	// StepVisitor is called for every step walked, along with the path leading to it (see Walk).
+	// Returning SkipChildren skips the children of the step, any other error stops the walk.
+	StepVisitor func(step DrawableGraph, path []string) error
+)
+
+// KindOf the step, or StepKindCustom if it can't be inspected
+func KindOf(step DrawableGraph) StepKind {
+	if s, ok := step.(InspectableStep); ok {
+		return s.Kind()
+	}
+	return StepKindCustom
+}
+
+// ChildrenOf the step, or none if it can't be inspected. Missing branches are nil
+func ChildrenOf(step DrawableGraph) []DrawableGraph {
+	if s, ok := step.(InspectableStep); ok {
+		return s.Children()
+	}
+	return nil
+}
+
+// Walk the step tree depth-first, calling the visitor for every (non nil) step in it.
+//
+// The path of a step is made of a segment per step leading to it (itself included). Units are identified by
+// their names, other steps by their kind (and name, if they have one, eg. "conditional:is_even").
+// The branches of a step are identified by their own segment, "true" and "false" for conditionals and the
+// index for sequential and concurrent ones, so every step of the tree has a different path. Eg.
+//
+//	sequential/1/concurrent/1/get_location
+//	sequential/1/conditional:is_close/true/notify_driver_close
+//
+// These are the same paths a StepError has, so the step that failed can be looked up (see FindByPath).
+//
+// The error returned by the visitor (other than SkipChildren) is returned.
+func Walk(step DrawableGraph, visitor StepVisitor) error {
+	if step == nil {
+		return nil
+	}
+	return walk(step, []string{stepSegment(step)}, visitor)
+}
+
+// FindByPath the step of the tree with the given path (see Walk), eg. the one of a StepError.
+// Returns false if there's none.
+func FindByPath(step DrawableGraph, path []string) (DrawableGraph, bool) {
+	var res DrawableGraph
+	_ = Walk(step, func(s DrawableGraph, p []string) error {
+		if len(p) > len(path) || !equalPaths(p, path[:len(p)]) {
+			return SkipChildren
+		}
+		if len(p) == len(path) {
+			res = s
+			return errFound
+		}
+		return nil
+	})
+	return res, res != nil
+}
+
+// FindByName the steps of the tree with the given name, in walking order
+func FindByName(step DrawableGraph, name string) []DrawableGraph {
+	var res []DrawableGraph
+	_ = Walk(step, func(s DrawableGraph, _ []string) error {
+		if n, ok := s.(interface{ Name() string }); ok && n.Name() == name {
+			res = append(res, s)
+		}
+		return nil
+	})
+	return res
+}
+
+// CountUnits of the tree
+func CountUnits(step DrawableGraph) int {
+	var count int
+	_ = Walk(step, func(s DrawableGraph, _ []string) error {
+		if KindOf(s) == StepKindUnit {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// MaxDepth of the tree, a single step has a depth of 1
+func MaxDepth(step DrawableGraph) int {
+	if step == nil {
+		return 0
+	}
+
+	var depth int
+	for _, c := range ChildrenOf(step) {
+		if d := MaxDepth(c); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// MaxParallelism of the tree, this is the maximum amount of steps that may run at the same time.
+// Units and custom steps that can't be inspected count as one.
+func MaxParallelism(step DrawableGraph) int {
+	if step == nil {
+		return 0
+	}
+
+	children := ChildrenOf(step)
+	if len(children) == 0 {
+		if KindOf(step) == StepKindConcurrent {
+			return 0
+		}
+		return 1
+	}
+
+	var res int
+	for _, c := range children {
+		p := MaxParallelism(c)
+		if KindOf(step) == StepKindConcurrent {
+			res += p
+		} else if p > res {
+			res = p
+		}
+	}
+	return res
+}
+
+func walk(step DrawableGraph, path []string, visitor StepVisitor) error {
+	if err := visitor(step, path); err != nil {
+		if errors.Is(err, SkipChildren) {
+			return nil
+		}
+		return err
+	}
+
+	for i, c := range ChildrenOf(step) {
+		if c == nil {
+			continue
+		}
+		if err := walk(c, childPath(step, path, i, c), visitor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childPath of the i-th child of the step, whose path is the given one
+func childPath(step DrawableGraph, path []string, i int, child DrawableGraph) []string {
+	branch := branchSegments(step, i)
+	res := make([]string, 0, len(path)+len(branch)+1)
+	res = append(res, path...)
+	res = append(res, branch...)
+	return append(res, stepSegment(child))
+}
+
+// stepSegments leading from the step to its i-th child: the segment of the step, followed by the one of the branch
+// (if the step branches). Used for building paths bottom-up, as errors propagate (see StepError)
+func stepSegments(step DrawableGraph, i int) []string {
+	return append([]string{stepSegment(step)}, branchSegments(step, i)...)
+}
+
+// branchSegments identifying the branch of the i-th child of the step, none if it's the only child the step has
+func branchSegments(step DrawableGraph, i int) []string {
+	switch KindOf(step) {
+	case StepKindConditional:
+		return []string{fmt.Sprint(i == 0)}
+	case StepKindSequential, StepKindConcurrent:
+		return []string{fmt.Sprint(i)}
+	default:
+		return nil
+	}
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stepSegment identifying the step in a path
+func stepSegment(step DrawableGraph) string {
+	kind := KindOf(step)
+
+	var name string
+	if n, ok := step.(interface{ Name() string }); ok {
+		name = n.Name()
+	}
+
+	switch {
+	case kind == StepKindUnit:
+		return name
+	case len(name) > 0:
+		return fmt.Sprintf("%s:%s", kind, name)
+	default:
+		return string(kind)
+	}
+}
@@ -0,0 +1,154 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func newIntrospectedUnit(name string) pipeline.UnitStep[int, int] {
+	return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+}
+
+func newIntrospectedPipeline() pipeline.Step[int, int] {
+	isEven := pipeline.NewStatement("is_even", func(ctx context.Context, i int) bool {
+		return i%2 == 0
+	})
+	sum := func(ctx context.Context, a, b int) (int, error) {
+		return a + b, nil
+	}
+
+	return pipeline.NewSequentialStep[int, int, int](
+		pipeline.NewTracedStepWithTracer[int, int]("trace", newIntrospectedUnit("first"), pipeline.NewRecordingTracer()),
+		pipeline.NewSequentialStep[int, int, int](
+			pipeline.NewConcurrentStep([]pipeline.Step[int, int]{
+				newIntrospectedUnit("a"),
+				pipeline.NewConcurrentStep([]pipeline.Step[int, int]{
+					newIntrospectedUnit("b"),
+					newIntrospectedUnit("c"),
+				}, sum),
+			}, sum),
+			pipeline.NewConditionalStep(isEven, newIntrospectedUnit("a"), nil),
+		),
+	)
+}
+
+func TestWalk_GivenAPipeline_WhenWalked_ThenEveryStepIsVisitedWithItsPath(t *testing.T) {
+	var paths []string
+
+	err := pipeline.Walk(newIntrospectedPipeline(), func(step pipeline.DrawableGraph, path []string) error {
+		paths = append(paths, strings.Join(path, "/"))
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"sequential",
+		"sequential/0/traced:trace",
+		"sequential/0/traced:trace/first",
+		"sequential/1/sequential",
+		"sequential/1/sequential/0/concurrent",
+		"sequential/1/sequential/0/concurrent/0/a",
+		"sequential/1/sequential/0/concurrent/1/concurrent",
+		"sequential/1/sequential/0/concurrent/1/concurrent/0/b",
+		"sequential/1/sequential/0/concurrent/1/concurrent/1/c",
+		"sequential/1/sequential/1/conditional:is_even",
+		"sequential/1/sequential/1/conditional:is_even/true/a",
+	}, paths)
+}
+
+func TestWalk_GivenAVisitorSkippingChildren_WhenWalked_ThenChildrenAreNotVisited(t *testing.T) {
+	var kinds []pipeline.StepKind
+
+	err := pipeline.Walk(newIntrospectedPipeline(), func(step pipeline.DrawableGraph, path []string) error {
+		kinds = append(kinds, pipeline.KindOf(step))
+		if len(path) == 3 {
+			return pipeline.SkipChildren
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []pipeline.StepKind{pipeline.StepKindSequential, pipeline.StepKindTraced, pipeline.StepKindSequential}, kinds)
+}
+
+func TestWalk_GivenAFailingVisitor_WhenWalked_ThenWalkStopsWithTheError(t *testing.T) {
+	expectedErr := errors.New("some error")
+	var visits int
+
+	err := pipeline.Walk(newIntrospectedPipeline(), func(step pipeline.DrawableGraph, path []string) error {
+		visits++
+		if pipeline.KindOf(step) == pipeline.StepKindUnit {
+			return expectedErr
+		}
+		return nil
+	})
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 3, visits)
+}
+
+func TestKindOf_GivenACustomStep_ThenItIsCustomAndHasNoChildren(t *testing.T) {
+	step := new(mockStep[int, int])
+
+	assert.Equal(t, pipeline.StepKindCustom, pipeline.KindOf(step))
+	assert.Nil(t, pipeline.ChildrenOf(step))
+}
+
+func TestKindOf_GivenTheBuiltInSteps_ThenTheirKindIsReturned(t *testing.T) {
+	unit := newIntrospectedUnit("unit")
+	stmt := pipeline.NewStatement("stmt", func(ctx context.Context, i int) bool { return true })
+
+	assert.Equal(t, pipeline.StepKindUnit, pipeline.KindOf(unit))
+	assert.Equal(t, pipeline.StepKindOptional, pipeline.KindOf(pipeline.NewOptionalStep[int](stmt, unit)))
+	assert.Equal(t, pipeline.StepKindHaltBoundary, pipeline.KindOf(pipeline.NewHaltBoundaryStep[int, int](unit)))
+	assert.Equal(t, pipeline.StepKindMetrics, pipeline.KindOf(
+		pipeline.NewMetricsStep[int, int]("metrics", unit, new(mockMetricsCollector)),
+	))
+}
+
+func TestFindByName_GivenAPipeline_ThenEveryStepWithTheNameIsFound(t *testing.T) {
+	steps := pipeline.FindByName(newIntrospectedPipeline(), "a")
+
+	assert.Len(t, steps, 2)
+	for _, s := range steps {
+		assert.Equal(t, pipeline.StepKindUnit, pipeline.KindOf(s))
+	}
+	assert.Len(t, pipeline.FindByName(newIntrospectedPipeline(), "is_even"), 1)
+	assert.Empty(t, pipeline.FindByName(newIntrospectedPipeline(), "missing"))
+}
+
+func TestFindByPath_GivenAPipeline_ThenTheStepAtThePathIsFound(t *testing.T) {
+	step, ok := pipeline.FindByPath(newIntrospectedPipeline(), []string{"sequential", "1", "sequential", "0", "concurrent", "1", "concurrent", "0", "b"})
+
+	assert.True(t, ok)
+	assert.Equal(t, "b", step.(pipeline.UnitStep[int, int]).Name())
+}
+
+func TestFindByPath_GivenAnUnknownPath_ThenNothingIsFound(t *testing.T) {
+	_, ok := pipeline.FindByPath(newIntrospectedPipeline(), []string{"sequential", "1", "sequential", "0", "concurrent", "2"})
+
+	assert.False(t, ok)
+}
+
+func TestCountUnits_GivenAPipeline_ThenUnitsAreCounted(t *testing.T) {
+	assert.Equal(t, 5, pipeline.CountUnits(newIntrospectedPipeline()))
+}
+
+func TestMaxDepth_GivenAPipeline_ThenTheDeepestBranchIsMeasured(t *testing.T) {
+	assert.Equal(t, 5, pipeline.MaxDepth(newIntrospectedPipeline()))
+	assert.Equal(t, 1, pipeline.MaxDepth(newIntrospectedUnit("unit")))
+	assert.Equal(t, 0, pipeline.MaxDepth(nil))
+}
+
+func TestMaxParallelism_GivenAPipeline_ThenTheWidestForkIsMeasured(t *testing.T) {
+	assert.Equal(t, 3, pipeline.MaxParallelism(newIntrospectedPipeline()))
+	assert.Equal(t, 1, pipeline.MaxParallelism(newIntrospectedUnit("unit")))
+}
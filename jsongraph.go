@@ -0,0 +1,396 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// GraphModelVersion is the version of the GraphModel schema produced by this package.
+	// It will be increased whenever a backwards incompatible change is introduced to the schema.
+	GraphModelVersion = 1
+
+	// GraphNodeStart is the single entry node of a graph
+	GraphNodeStart GraphNodeKind = "start"
+	// GraphNodeEnd is the single exit node of a graph
+	GraphNodeEnd GraphNodeKind = "end"
+	// GraphNodeActivity is an activity (see Graph.AddActivity)
+	GraphNodeActivity GraphNodeKind = "activity"
+	// GraphNodeDecision opens a decision (see Graph.AddDecision). It has a "yes" and a "no" labeled edge
+	GraphNodeDecision GraphNodeKind = "decision"
+	// GraphNodeMerge closes a decision, every branch of it ends in this node
+	GraphNodeMerge GraphNodeKind = "merge"
+	// GraphNodeFork opens a concurrency (see Graph.AddConcurrency). It has an edge per concurrent branch
+	GraphNodeFork GraphNodeKind = "fork"
+	// GraphNodeJoin closes a concurrency, every branch of it ends in this node
+	GraphNodeJoin GraphNodeKind = "join"
+)
+
+type (
+	// GraphNodeKind is the kind of a node of a GraphModel
+	GraphNodeKind string
+
+	// GraphModel is a serializable model of a drawn graph, made of nodes and edges.
+	//
+	// Its JSON schema (version 1) is:
+	//
+	//	{
+	//	  "version": 1,
+	//	  "nodes": [
+	//	    {"id": "n1", "kind": "activity", "label": "name_of_the_step"},
+	//	    {"id": "n2", "kind": "decision", "label": "name_of_the_statement", "close": "n5"}
+	//	  ],
+	//	  "edges": [
+	//	    {"from": "start", "to": "n1"},
+	//	    {"from": "n2", "to": "n3", "label": "yes"}
+	//	  ]
+	//	}
+	//
+	// Where:
+	//   - node IDs are unique. The start node has ID "start" and the end node ID "end".
+	//   - node kinds are the ones described by the GraphNodeKind constants.
+	//   - decision and fork nodes have a "close" field with the ID of their merge or join node.
+	//   - decision edges are labeled "yes" and "no". Fork edges are in the order of the branches.
+	//
+	// A GraphModel is drawable, so it can be re-rendered through any Graph implementation.
+	GraphModel struct {
+		Version int         `json:"version"`
+		Nodes   []GraphNode `json:"nodes"`
+		Edges   []GraphEdge `json:"edges"`
+	}
+
+	// GraphNode is a node of a GraphModel
+	GraphNode struct {
+		ID    string        `json:"id"`
+		Kind  GraphNodeKind `json:"kind"`
+		Label string        `json:"label,omitempty"`
+		// Close is the ID of the node closing a decision or fork
+		Close string `json:"close,omitempty"`
+	}
+
+	// GraphEdge is a directed edge of a GraphModel
+	GraphEdge struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Label string `json:"label,omitempty"`
+	}
+
+	// JSONGraph represents a graph that builds a GraphModel, rendering itself into JSON
+	JSONGraph struct {
+		model GraphModel
+		count int
+		// tails are the nodes (and the label of their edge) that will be linked to the next node added
+		tails []graphTail
+	}
+)
+
+// NewJSONGraph creates a graph that models the drawings made to it
+func NewJSONGraph() *JSONGraph {
+	return &JSONGraph{
+		model: GraphModel{
+			Version: GraphModelVersion,
+			Nodes:   []GraphNode{{ID: string(GraphNodeStart), Kind: GraphNodeStart}},
+		},
+		tails: []graphTail{{id: string(GraphNodeStart)}},
+	}
+}
+
+// ParseGraphModel parses a GraphModel from its JSON representation.
+//
+// The model is checked to be well-formed as described by the schema, so it can be safely drawn. Eg. edges must link
+// existing nodes, graphs can't have cycles and every decision and fork must be closed by a merge or join node that
+// all of their branches reach.
+func ParseGraphModel(data []byte) (GraphModel, error) {
+	var m GraphModel
+	if err := json.Unmarshal(data, &m); err != nil {
+		return GraphModel{}, err
+	}
+	if m.Version != GraphModelVersion {
+		return GraphModel{}, fmt.Errorf("unsupported graph model version %d, expected %d", m.Version, GraphModelVersion)
+	}
+	if err := m.validate(); err != nil {
+		return GraphModel{}, fmt.Errorf("invalid graph model: %w", err)
+	}
+	return m, nil
+}
+
+func (j *JSONGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	id := j.node(GraphNodeDecision, statement)
+
+	j.tails = []graphTail{{id: id, label: "yes"}}
+	yes(j)
+	yesTails := j.tails
+
+	j.tails = []graphTail{{id: id, label: "no"}}
+	no(j)
+
+	j.tails = append(yesTails, j.tails...)
+	j.close(id, j.node(GraphNodeMerge, ""))
+}
+
+func (j *JSONGraph) AddConcurrency(forks ...GraphDrawer) {
+	if len(forks) == 0 {
+		return
+	}
+
+	id := j.node(GraphNodeFork, "")
+	var tails []graphTail
+	for _, f := range forks {
+		j.tails = []graphTail{{id: id}}
+		f(j)
+		tails = append(tails, j.tails...)
+	}
+
+	j.tails = tails
+	j.close(id, j.node(GraphNodeJoin, ""))
+}
+
+func (j *JSONGraph) AddActivity(label string) {
+	j.node(GraphNodeActivity, label)
+}
+
+// Model returns the model of the graph, including its end node
+func (j *JSONGraph) Model() GraphModel {
+	m := GraphModel{
+		Version: j.model.Version,
+		Nodes:   append([]GraphNode(nil), j.model.Nodes...),
+		Edges:   append([]GraphEdge(nil), j.model.Edges...),
+	}
+	m.Nodes = append(m.Nodes, GraphNode{ID: string(GraphNodeEnd), Kind: GraphNodeEnd})
+	for _, t := range j.tails {
+		m.Edges = append(m.Edges, GraphEdge{From: t.id, To: string(GraphNodeEnd), Label: t.label})
+	}
+	return m
+}
+
+// String returns the JSON representation of the model of the graph
+func (j *JSONGraph) String() string {
+	b, _ := json.MarshalIndent(j.Model(), "", "  ")
+	return string(b) + "\n"
+}
+
+func (j *JSONGraph) node(kind GraphNodeKind, label string) string {
+	j.count++
+	id := fmt.Sprintf("n%d", j.count)
+
+	j.model.Nodes = append(j.model.Nodes, GraphNode{ID: id, Kind: kind, Label: label})
+	for _, t := range j.tails {
+		j.model.Edges = append(j.model.Edges, GraphEdge{From: t.id, To: id, Label: t.label})
+	}
+	j.tails = []graphTail{{id: id}}
+	return id
+}
+
+func (j *JSONGraph) close(open, closing string) {
+	for i := range j.model.Nodes {
+		if j.model.Nodes[i].ID == open {
+			j.model.Nodes[i].Close = closing
+			return
+		}
+	}
+}
+
+// validate the model is well-formed, so drawing it terminates and draws every node
+func (m GraphModel) validate() error {
+	nodes := make(map[string]GraphNode, len(m.Nodes))
+	for _, n := range m.Nodes {
+		if _, ok := nodes[n.ID]; ok {
+			return fmt.Errorf("duplicate node %q", n.ID)
+		}
+		switch n.Kind {
+		case GraphNodeStart, GraphNodeEnd:
+			if n.ID != string(n.Kind) {
+				return fmt.Errorf("%s node with ID %q, expected %q", n.Kind, n.ID, n.Kind)
+			}
+		case GraphNodeActivity, GraphNodeDecision, GraphNodeMerge, GraphNodeFork, GraphNodeJoin:
+		default:
+			return fmt.Errorf("node %q of unknown kind %q", n.ID, n.Kind)
+		}
+		nodes[n.ID] = n
+	}
+	for _, k := range []GraphNodeKind{GraphNodeStart, GraphNodeEnd} {
+		if _, ok := nodes[string(k)]; !ok {
+			return fmt.Errorf("missing %s node", k)
+		}
+	}
+
+	out := make(map[string][]GraphEdge, len(m.Nodes))
+	for _, e := range m.Edges {
+		for _, id := range []string{e.From, e.To} {
+			if _, ok := nodes[id]; !ok {
+				return fmt.Errorf("edge from %q to %q links unknown node %q", e.From, e.To, id)
+			}
+		}
+		out[e.From] = append(out[e.From], e)
+	}
+
+	for _, n := range m.Nodes {
+		if err := validateGraphNode(n, nodes, out[n.ID]); err != nil {
+			return err
+		}
+	}
+	if err := validateAcyclic(m.Nodes, out); err != nil {
+		return err
+	}
+
+	// every branch must reach the node closing it. As the graph is acyclic, following them terminates
+	closed := make(map[string]bool)
+	var follow func(id, until string) error
+	follow = func(id, until string) error {
+		for id != until {
+			n := nodes[id]
+			switch n.Kind {
+			case GraphNodeEnd:
+				return fmt.Errorf("branch doesn't reach its closing node %q", until)
+			case GraphNodeDecision, GraphNodeFork:
+				if !closed[n.ID] {
+					for _, e := range out[n.ID] {
+						if err := follow(e.To, n.Close); err != nil {
+							return fmt.Errorf("%s %q: %w", n.Kind, n.ID, err)
+						}
+					}
+					closed[n.ID] = true
+				}
+				id = n.Close
+			}
+			id = out[id][0].To
+		}
+		return nil
+	}
+	return follow(out[string(GraphNodeStart)][0].To, string(GraphNodeEnd))
+}
+
+// validateGraphNode checks the edges leaving the node and the node closing it (if any)
+func validateGraphNode(n GraphNode, nodes map[string]GraphNode, out []GraphEdge) error {
+	switch n.Kind {
+	case GraphNodeEnd:
+		if len(out) > 0 {
+			return fmt.Errorf("end node with outgoing edges")
+		}
+		return nil
+	case GraphNodeDecision:
+		if len(out) != 2 || out[0].Label == out[1].Label || (out[0].Label != "yes" && out[0].Label != "no") ||
+			(out[1].Label != "yes" && out[1].Label != "no") {
+			return fmt.Errorf("decision %q without a single yes and no edge", n.ID)
+		}
+		return validateGraphClose(n, nodes, GraphNodeMerge)
+	case GraphNodeFork:
+		if len(out) == 0 {
+			return fmt.Errorf("fork %q without branches", n.ID)
+		}
+		return validateGraphClose(n, nodes, GraphNodeJoin)
+	default:
+		if len(out) != 1 {
+			return fmt.Errorf("%s node %q with %d outgoing edges, expected 1", n.Kind, n.ID, len(out))
+		}
+		return nil
+	}
+}
+
+// validateGraphClose checks the node is closed by an existing one of the given kind
+func validateGraphClose(n GraphNode, nodes map[string]GraphNode, kind GraphNodeKind) error {
+	if len(n.Close) == 0 {
+		return fmt.Errorf("%s %q without close", n.Kind, n.ID)
+	}
+	if c, ok := nodes[n.Close]; !ok || c.Kind != kind {
+		return fmt.Errorf("%s %q closed by %q, which isn't a %s node", n.Kind, n.ID, n.Close, kind)
+	}
+	return nil
+}
+
+// validateAcyclic checks the edges don't form a cycle
+func validateAcyclic(nodes []GraphNode, out map[string][]GraphEdge) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(nodes))
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("cycle through node %q", id)
+		case visited:
+			return nil
+		}
+
+		state[id] = visiting
+		for _, e := range out[id] {
+			if err := visit(e.To); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+
+	for _, n := range nodes {
+		if err := visit(n.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draw the modeled graph into another one, so it can be re-rendered.
+// The model is expected to be well-formed, as the ones created by a JSONGraph or parsed by ParseGraphModel.
+func (m GraphModel) Draw(graph Graph) {
+	nodes := make(map[string]GraphNode, len(m.Nodes))
+	for _, n := range m.Nodes {
+		nodes[n.ID] = n
+	}
+	out := make(map[string][]GraphEdge, len(m.Nodes))
+	for _, e := range m.Edges {
+		out[e.From] = append(out[e.From], e)
+	}
+
+	var walk func(graph Graph, id, until string)
+	walk = func(graph Graph, id, until string) {
+		for id != until {
+			n, ok := nodes[id]
+			if !ok || n.Kind == GraphNodeEnd {
+				return
+			}
+
+			switch n.Kind {
+			case GraphNodeActivity:
+				graph.AddActivity(n.Label)
+			case GraphNodeDecision:
+				var yes, no string
+				for _, e := range out[n.ID] {
+					if e.Label == "yes" {
+						yes = e.To
+					} else {
+						no = e.To
+					}
+				}
+				closing := n.Close
+				graph.AddDecision(
+					n.Label,
+					func(graph Graph) { walk(graph, yes, closing) },
+					func(graph Graph) { walk(graph, no, closing) },
+				)
+				id = closing
+			case GraphNodeFork:
+				var branches []GraphDrawer
+				for _, e := range out[n.ID] {
+					to, closing := e.To, n.Close
+					branches = append(branches, func(graph Graph) { walk(graph, to, closing) })
+				}
+				graph.AddConcurrency(branches...)
+				id = n.Close
+			}
+
+			next := out[id]
+			if len(next) == 0 {
+				return
+			}
+			id = next[0].To
+		}
+	}
+
+	if start := out[string(GraphNodeStart)]; len(start) > 0 {
+		walk(graph, start[0].To, string(GraphNodeEnd))
+	}
+}
@@ -0,0 +1,150 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func drawComplexGraph(graph pipeline.Graph) {
+	graph.AddActivity("beginning")
+	graph.AddConcurrency(func(graph pipeline.Graph) {
+		graph.AddActivity("branch 1")
+		graph.AddActivity("branch 1 again")
+	}, func(graph pipeline.Graph) {
+		graph.AddDecision("is this a test?", func(graph pipeline.Graph) {
+			graph.AddActivity("yes, this is a test")
+		}, func(graph pipeline.Graph) {})
+	}, func(graph pipeline.Graph) {})
+	graph.AddDecision("nested?", func(graph pipeline.Graph) {
+		graph.AddDecision("inner", func(graph pipeline.Graph) {}, func(graph pipeline.Graph) {
+			graph.AddActivity("inner no")
+		})
+	}, func(graph pipeline.Graph) {
+		graph.AddActivity("outer no")
+	})
+	graph.AddActivity("end")
+}
+
+func TestJSONGraph_GivenAGraph_WhenModeled_ThenNodesAndEdgesAreCaptured(t *testing.T) {
+	diagram := pipeline.NewJSONGraph()
+	diagram.AddActivity("first")
+	diagram.AddDecision("cond", func(graph pipeline.Graph) {
+		graph.AddActivity("yes")
+	}, func(graph pipeline.Graph) {})
+
+	model := diagram.Model()
+
+	assert.Equal(t, pipeline.GraphModel{
+		Version: pipeline.GraphModelVersion,
+		Nodes: []pipeline.GraphNode{
+			{ID: "start", Kind: pipeline.GraphNodeStart},
+			{ID: "n1", Kind: pipeline.GraphNodeActivity, Label: "first"},
+			{ID: "n2", Kind: pipeline.GraphNodeDecision, Label: "cond", Close: "n4"},
+			{ID: "n3", Kind: pipeline.GraphNodeActivity, Label: "yes"},
+			{ID: "n4", Kind: pipeline.GraphNodeMerge},
+			{ID: "end", Kind: pipeline.GraphNodeEnd},
+		},
+		Edges: []pipeline.GraphEdge{
+			{From: "start", To: "n1"},
+			{From: "n1", To: "n2"},
+			{From: "n2", To: "n3", Label: "yes"},
+			{From: "n3", To: "n4"},
+			{From: "n2", To: "n4", Label: "no"},
+			{From: "n4", To: "end"},
+		},
+	}, model)
+}
+
+func TestJSONGraph_GivenAGraph_WhenStringRepresentationIsAsked_ThenVersionedJSONIsReturned(t *testing.T) {
+	diagram := pipeline.NewJSONGraph()
+	diagram.AddActivity("first")
+
+	assert.JSONEq(t, `{
+		"version": 1,
+		"nodes": [
+			{"id": "start", "kind": "start"},
+			{"id": "n1", "kind": "activity", "label": "first"},
+			{"id": "end", "kind": "end"}
+		],
+		"edges": [
+			{"from": "start", "to": "n1"},
+			{"from": "n1", "to": "end"}
+		]
+	}`, diagram.String())
+}
+
+func TestGraphModel_GivenAParsedModel_WhenDrawnIntoAnotherGraph_ThenItRoundTrips(t *testing.T) {
+	diagram := pipeline.NewJSONGraph()
+	drawComplexGraph(diagram)
+	expected := pipeline.NewUMLGraph()
+	drawComplexGraph(expected)
+
+	model, err := pipeline.ParseGraphModel([]byte(diagram.String()))
+	actual := pipeline.NewUMLGraph()
+	model.Draw(actual)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected.String(), actual.String())
+}
+
+func TestGraphModel_GivenAnEmptyModel_WhenDrawn_ThenNothingIsDrawn(t *testing.T) {
+	actual := pipeline.NewUMLGraph()
+
+	pipeline.NewJSONGraph().Model().Draw(actual)
+
+	assert.Equal(t, pipeline.NewUMLGraph().String(), actual.String())
+}
+
+func TestParseGraphModel_GivenAnUnsupportedVersion_ThenErrorIsReturned(t *testing.T) {
+	_, err := pipeline.ParseGraphModel([]byte(`{"version": 2}`))
+
+	assert.EqualError(t, err, "unsupported graph model version 2, expected 1")
+}
+
+func TestParseGraphModel_GivenInvalidJSON_ThenErrorIsReturned(t *testing.T) {
+	_, err := pipeline.ParseGraphModel([]byte(`{`))
+
+	assert.NotNil(t, err)
+}
+
+const (
+	graphModelStart = `{"id": "start", "kind": "start"}`
+	graphModelEnd   = `{"id": "end", "kind": "end"}`
+)
+
+func assertMalformedGraphModel(t *testing.T, nodes, edges, expectedErr string) {
+	_, err := pipeline.ParseGraphModel([]byte(`{"version": 1, "nodes": [` + nodes + `], "edges": [` + edges + `]}`))
+
+	assert.EqualError(t, err, "invalid graph model: "+expectedErr)
+}
+
+func TestParseGraphModel_GivenAnEdgeToAnUnknownNode_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart+`,`+graphModelEnd, `{"from": "start", "to": "n1"}`, `edge from "start" to "n1" links unknown node "n1"`)
+}
+
+func TestParseGraphModel_GivenANodeOfUnknownKind_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart+`,`+graphModelEnd+`,{"id": "n1", "kind": "loop"}`, `{"from": "start", "to": "n1"}, {"from": "n1", "to": "end"}`, `node "n1" of unknown kind "loop"`)
+}
+
+func TestParseGraphModel_GivenNoEndNode_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart, ``, "missing end node")
+}
+
+func TestParseGraphModel_GivenADecisionWithoutClose_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart+`,`+graphModelEnd+`,{"id": "n1", "kind": "decision"}`, `{"from": "start", "to": "n1"}, {"from": "n1", "to": "end", "label": "yes"}, {"from": "n1", "to": "end", "label": "no"}`, `decision "n1" without close`)
+}
+
+func TestParseGraphModel_GivenAForkClosedByAnUnknownNode_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart+`,`+graphModelEnd+`,{"id": "n1", "kind": "fork", "close": "n2"}`, `{"from": "start", "to": "n1"}, {"from": "n1", "to": "end"}`, `fork "n1" closed by "n2", which isn't a join node`)
+}
+
+func TestParseGraphModel_GivenACycle_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart+`,`+graphModelEnd+`,{"id": "n1", "kind": "activity"},{"id": "n2", "kind": "activity"}`, `{"from": "start", "to": "n1"}, {"from": "n1", "to": "n2"}, {"from": "n2", "to": "n1"}`, `cycle through node "n1"`)
+}
+
+func TestParseGraphModel_GivenABranchNotReachingItsClose_ThenErrorIsReturned(t *testing.T) {
+	assertMalformedGraphModel(t, graphModelStart+`,`+graphModelEnd+`,{"id": "n1", "kind": "decision", "close": "n2"},{"id": "n2", "kind": "merge"}`, `{"from": "start", "to": "n1"}, {"from": "n1", "to": "n2", "label": "yes"}, {"from": "n1", "to": "end", "label": "no"}, {"from": "n2", "to": "end"}`, `decision "n1": branch doesn't reach its closing node "n2"`)
+}
@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// IDs of the start and end nodes of mermaid graphs
+	mermaidStartID = "start"
+	mermaidEndID   = "stop"
+)
+
+type (
+	// MermaidGraph represents a graph that can render itself into a Mermaid flowchart.
+	//
+	// Node IDs are assigned sequentially in drawing order, so drawing the same step always yields
+	// the same output (allowing it to be diffed cleanly).
+	MermaidGraph struct {
+		sb    strings.Builder
+		count int
+		// tails are the nodes (and the label of their edge) that will be linked to the next node added
+		tails []graphTail
+	}
+)
+
+// NewMermaidGraph creates a Mermaid flowchart diagram that represents one
+func NewMermaidGraph() *MermaidGraph {
+	return &MermaidGraph{
+		tails: []graphTail{{id: mermaidStartID}},
+	}
+}
+
+func (m *MermaidGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	id := m.node("{%s}", statement)
+
+	m.tails = []graphTail{{id: id, label: "yes"}}
+	yes(m)
+	yesTails := m.tails
+
+	m.tails = []graphTail{{id: id, label: "no"}}
+	no(m)
+
+	m.tails = append(yesTails, m.tails...)
+}
+
+func (m *MermaidGraph) AddConcurrency(forks ...GraphDrawer) {
+	if len(forks) == 0 {
+		return
+	}
+
+	fork := m.bar()
+	var tails []graphTail
+	for _, f := range forks {
+		m.tails = []graphTail{{id: fork}}
+		f(m)
+		tails = append(tails, m.tails...)
+	}
+
+	m.tails = tails
+	m.bar()
+}
+
+func (m *MermaidGraph) AddActivity(label string) {
+	m.node("[%s]", label)
+}
+
+func (m *MermaidGraph) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("flowchart TD\n")
+	sb.WriteString("    classDef bar fill:#000,stroke:#000\n")
+	sb.WriteString(fmt.Sprintf("    %s((start))\n", mermaidStartID))
+
+	sb.WriteString(m.sb.String())
+
+	sb.WriteString(fmt.Sprintf("    %s((end))\n", mermaidEndID))
+	for _, t := range m.tails {
+		sb.WriteString(mermaidEdge(t, mermaidEndID))
+	}
+
+	return sb.String()
+}
+
+// node adds a node with the given shape (a format wrapping the label) linking the pending tails to it
+func (m *MermaidGraph) node(shape, label string) string {
+	m.count++
+	id := fmt.Sprintf("n%d", m.count)
+
+	m.sb.WriteString(fmt.Sprintf("    %s"+shape+"\n", id, fmt.Sprintf("\"%s\"", escapeMermaid(label))))
+	m.link(id)
+	return id
+}
+
+// bar adds a fork/join bar linking the pending tails to it
+func (m *MermaidGraph) bar() string {
+	m.count++
+	id := fmt.Sprintf("n%d", m.count)
+
+	m.sb.WriteString(fmt.Sprintf("    %s[\" \"]:::bar\n", id))
+	m.link(id)
+	return id
+}
+
+func (m *MermaidGraph) link(id string) {
+	for _, t := range m.tails {
+		m.sb.WriteString(mermaidEdge(t, id))
+	}
+	m.tails = []graphTail{{id: id}}
+}
+
+func mermaidEdge(from graphTail, to string) string {
+	if len(from.label) == 0 {
+		return fmt.Sprintf("    %s --> %s\n", from.id, to)
+	}
+	return fmt.Sprintf("    %s -->|\"%s\"| %s\n", from.id, escapeMermaid(from.label), to)
+}
+
+// escapeMermaid escapes a label so it can be safely placed between double quotes
+func escapeMermaid(label string) string {
+	return strings.NewReplacer(
+		"#", "#35;",
+		"\"", "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+		"\r\n", "<br>",
+		"\n", "<br>",
+	).Replace(label)
+}
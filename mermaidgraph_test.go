@@ -0,0 +1,88 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestMermaidGraph_GivenAnEmptyGraph_WhenStringRepresentationIsAsked_ThenStartIsLinkedToEnd(t *testing.T) {
+	diagram := pipeline.NewMermaidGraph()
+
+	content := diagram.String()
+
+	assert.Equal(t, "flowchart TD\n    classDef bar fill:#000,stroke:#000\n    start((start))\n    stop((end))\n    start --> stop\n", content)
+}
+
+func TestMermaidGraph_GivenAGraph_WhenAddingActivities_ThenNodesAreLinkedSequentially(t *testing.T) {
+	diagram := pipeline.NewMermaidGraph()
+	diagram.AddActivity("1")
+	diagram.AddActivity("2")
+
+	content := diagram.String()
+
+	assert.Contains(t, content, "    n1[\"1\"]\n    start --> n1\n    n2[\"2\"]\n    n1 --> n2\n    stop((end))\n    n2 --> stop\n")
+}
+
+func TestMermaidGraph_GivenAGraph_WhenAddingADecision_ThenDiamondWithLabeledBranchesIsAdded(t *testing.T) {
+	diagram := pipeline.NewMermaidGraph()
+	diagram.AddDecision("is this a test?", func(graph pipeline.Graph) {
+		graph.AddActivity("yes, this is a test")
+	}, func(graph pipeline.Graph) {})
+	diagram.AddActivity("after")
+
+	content := diagram.String()
+
+	assert.Contains(t, content, "    n1{\"is this a test?\"}\n    start --> n1\n"+
+		"    n2[\"yes, this is a test\"]\n    n1 -->|\"yes\"| n2\n"+
+		"    n3[\"after\"]\n    n2 --> n3\n    n1 -->|\"no\"| n3\n")
+}
+
+func TestMermaidGraph_GivenAGraph_WhenAddingConcurrency_ThenForkAndJoinAreAdded(t *testing.T) {
+	diagram := pipeline.NewMermaidGraph()
+	diagram.AddConcurrency(func(graph pipeline.Graph) {
+		graph.AddActivity("1")
+	}, func(graph pipeline.Graph) {
+		graph.AddActivity("2")
+	}, func(graph pipeline.Graph) {})
+
+	content := diagram.String()
+
+	assert.Equal(t, "flowchart TD\n    classDef bar fill:#000,stroke:#000\n    start((start))\n"+
+		"    n1[\" \"]:::bar\n    start --> n1\n"+
+		"    n2[\"1\"]\n    n1 --> n2\n"+
+		"    n3[\"2\"]\n    n1 --> n3\n"+
+		"    n4[\" \"]:::bar\n    n2 --> n4\n    n3 --> n4\n    n1 --> n4\n"+
+		"    stop((end))\n    n4 --> stop\n", content)
+}
+
+func TestMermaidGraph_GivenAGraph_WhenAddingZeroConcurrentCases_ThenNothingHappens(t *testing.T) {
+	diagram := pipeline.NewMermaidGraph()
+	diagram.AddConcurrency()
+
+	assert.Equal(t, pipeline.NewMermaidGraph().String(), diagram.String())
+}
+
+func TestMermaidGraph_GivenLabelsWithSpecialCharacters_WhenAdded_ThenTheyAreEscaped(t *testing.T) {
+	diagram := pipeline.NewMermaidGraph()
+	diagram.AddActivity("say \"hi\" <#1>\nplease")
+
+	content := diagram.String()
+
+	assert.Contains(t, content, "    n1[\"say #quot;hi#quot; #lt;#35;1#gt;<br>please\"]\n")
+}
+
+func TestMermaidGraph_GivenTheSameStep_WhenDrawnTwice_ThenOutputIsStable(t *testing.T) {
+	draw := func() string {
+		diagram := pipeline.NewMermaidGraph()
+		pipeline.NewSequentialStep[any, any, any](
+			pipeline.NewUnitStep[any, any]("1", nil),
+			pipeline.NewUnitStep[any, any]("2", nil),
+		).Draw(diagram)
+		return diagram.String()
+	}
+
+	assert.Equal(t, draw(), draw())
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"strings"
+)
+
+type (
+	// StepMetadata describes a unit for the people owning and operating it (see UnitStep.WithMetadata). It's drawn
+	// along the unit (eg. as a note in an UML graph) and reachable at runtime through StepCall.Metadata, which the
+	// tracing and metrics interceptors use for annotating spans and labeling metrics.
+	StepMetadata struct {
+		// Description of what the step does
+		Description string
+		// Owner of the step, eg. the team maintaining it
+		Owner string
+		// Tags categorizing the step
+		Tags []string
+		// Link to further documentation of the step (eg. a runbook)
+		Link string
+	}
+)
+
+// IsZero reports whether the metadata is empty
+func (m StepMetadata) IsZero() bool {
+	return len(m.Description) == 0 && len(m.Owner) == 0 && len(m.Tags) == 0 && len(m.Link) == 0
+}
+
+// Attributes of the metadata, for annotating spans. Empty fields are omitted
+func (m StepMetadata) Attributes() []Attribute {
+	var attrs []Attribute
+	if len(m.Description) > 0 {
+		attrs = append(attrs, NewAttribute("step.description", m.Description))
+	}
+	if len(m.Owner) > 0 {
+		attrs = append(attrs, NewAttribute("step.owner", m.Owner))
+	}
+	if len(m.Tags) > 0 {
+		attrs = append(attrs, NewAttribute("step.tags", strings.Join(m.Tags, ",")))
+	}
+	if len(m.Link) > 0 {
+		attrs = append(attrs, NewAttribute("step.link", m.Link))
+	}
+	return attrs
+}
+
+// Labels of the metadata, for labeling the metrics of the step (see LabeledMetricsCollector). Only the owner is
+// a label, as the other fields are either unbounded or multi-valued. Empty fields are omitted
+func (m StepMetadata) Labels() map[string]string {
+	if len(m.Owner) == 0 {
+		return nil
+	}
+	return map[string]string{"owner": m.Owner}
+}
+
+// String representation of the metadata, one line per non empty field
+func (m StepMetadata) String() string {
+	var lines []string
+	if len(m.Description) > 0 {
+		lines = append(lines, m.Description)
+	}
+	if len(m.Owner) > 0 {
+		lines = append(lines, "owner: "+m.Owner)
+	}
+	if len(m.Tags) > 0 {
+		lines = append(lines, "tags: "+strings.Join(m.Tags, ", "))
+	}
+	if len(m.Link) > 0 {
+		lines = append(lines, m.Link)
+	}
+	return strings.Join(lines, "\n")
+}
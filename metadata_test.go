@@ -0,0 +1,79 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestUnitStep_GivenMetadata_ThenItIsReturnedKeepingTheID(t *testing.T) {
+	md := pipeline.StepMetadata{Description: "does things", Owner: "team", Tags: []string{"a"}, Link: "http://docs"}
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+
+	described := step.WithMetadata(md)
+
+	assert.Equal(t, md, described.Metadata())
+	assert.Equal(t, step.ID(), described.ID())
+	assert.True(t, step.Metadata().IsZero())
+}
+
+func TestStepMetadata_GivenMetadata_ThenAttributesOmitEmptyFields(t *testing.T) {
+	md := pipeline.StepMetadata{Owner: "team", Tags: []string{"a", "b"}}
+
+	assert.Equal(t, []pipeline.Attribute{
+		pipeline.NewAttribute("step.owner", "team"),
+		pipeline.NewAttribute("step.tags", "a,b"),
+	}, md.Attributes())
+	assert.Nil(t, pipeline.StepMetadata{}.Attributes())
+}
+
+func TestStepMetadata_GivenMetadata_ThenStringHasALinePerField(t *testing.T) {
+	md := pipeline.StepMetadata{Description: "does things", Owner: "team", Tags: []string{"a", "b"}, Link: "http://docs"}
+
+	assert.Equal(t, "does things\nowner: team\ntags: a, b\nhttp://docs", md.String())
+}
+
+func TestUnitStep_GivenMetadata_WhenDrawnInAGraphWithoutNotes_ThenOnlyTheActivityIsDrawn(t *testing.T) {
+	graph := new(mockGraph)
+	graph.On("AddActivity", "unit").Once()
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}).WithMetadata(pipeline.StepMetadata{Owner: "team"})
+
+	step.Draw(graph)
+
+	graph.AssertExpectations(t)
+}
+
+func TestUnitStep_GivenMetadata_WhenCopied_ThenItKeepsItsID(t *testing.T) {
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+
+	assert.Equal(t, step.ID(), step.WithMetadata(pipeline.StepMetadata{Owner: "team"}).ID())
+}
+
+func TestUnitStep_GivenMetadata_WhenRunWithInterceptors_ThenCallsAndSpansCarryIt(t *testing.T) {
+	md := pipeline.StepMetadata{Owner: "team"}
+	var call pipeline.StepCall
+	tracer := pipeline.NewRecordingTracer()
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}).WithMetadata(md)
+	ctx := pipeline.WithInterceptors(
+		context.Background(),
+		pipeline.NewTracingInterceptor(tracer),
+		pipeline.InterceptorFuncs{AfterFunc: func(ctx context.Context, c pipeline.StepCall) { call = c }},
+	)
+
+	_, err := step.Run(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, md, call.Metadata)
+	assert.Equal(t, []pipeline.Attribute{pipeline.NewAttribute("step.owner", "team")}, tracer.Spans()[0].Attributes)
+}
@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// MetricsCollector collects metrics about step executions.
+	//
+	// Implementations must be safe for concurrent use, as steps may run concurrently.
+	MetricsCollector interface {
+		// StepStarted is called right before the named step runs
+		StepStarted(step string)
+		// StepFinished is called once the named step finished, with its duration and the error it returned (if any)
+		StepFinished(step string, duration time.Duration, err error)
+	}
+
+	// LabeledMetricsCollector is an optional interface a MetricsCollector can implement for collecting the
+	// executions of units along with the labels of their metadata (see StepMetadata.Labels). Interceptors created
+	// through NewMetricsInterceptor prefer it for units with metadata labels.
+	LabeledMetricsCollector interface {
+		// StepStartedWithLabels is called right before the named step runs
+		StepStartedWithLabels(step string, labels map[string]string)
+		// StepFinishedWithLabels is called once the named step finished, with its duration and the error it
+		// returned (if any)
+		StepFinishedWithLabels(step string, labels map[string]string, duration time.Duration, err error)
+	}
+
+	// MetricsStep decorates a step reporting its executions to a MetricsCollector.
+	MetricsStep[I, O any] struct {
+		name      string
+		step      Step[I, O]
+		collector MetricsCollector
+	}
+)
+
+// NewMetricsStep creates a step that will report its executions under the given name to the collector
+func NewMetricsStep[I, O any](name string, step Step[I, O], collector MetricsCollector) MetricsStep[I, O] {
+	return MetricsStep[I, O]{
+		name:      name,
+		step:      step,
+		collector: collector,
+	}
+}
+
+// Draw the inner step, stereotyping its activities as metered
+func (m MetricsStep[I, O]) Draw(graph Graph) {
+	DrawStereotyped(graph, "metrics", m.step.Draw)
+}
+
+// Name this step reports its executions under
+func (m MetricsStep[I, O]) Name() string {
+	return m.name
+}
+
+// Kind of this step, see StepKindMetrics
+func (m MetricsStep[I, O]) Kind() StepKind {
+	return StepKindMetrics
+}
+
+// Children of this step, the measured one
+func (m MetricsStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{m.step}
+}
+
+func (m MetricsStep[I, O]) problems() []string {
+	var res []string
+	if m.step == nil {
+		res = append(res, "nil measured step")
+	}
+	if m.collector == nil {
+		res = append(res, "nil collector")
+	}
+	return res
+}
+
+func (m MetricsStep[I, O]) Run(ctx context.Context, in I) (O, error) {
+	m.collector.StepStarted(m.name)
+	start := time.Now()
+
+	res, err := m.step.Run(withStepPath(ctx, m, 0), in)
+
+	m.collector.StepFinished(m.name, time.Since(start), err)
+	return res, prependStepPath(err, m, 0)
+}
@@ -0,0 +1,52 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+type mockMetricsCollector struct {
+	mock.Mock
+}
+
+func (m *mockMetricsCollector) StepStarted(step string) {
+	_ = m.Called(step)
+}
+
+func (m *mockMetricsCollector) StepFinished(step string, duration time.Duration, err error) {
+	_ = m.Called(step, duration, err)
+}
+
+func TestMetricsStep_GivenAStep_WhenRun_ThenCollectorIsNotifiedOfStartAndFinish(t *testing.T) {
+	expectedErr := errors.New("some error")
+	inner := new(mockStep[int, int])
+	inner.On("Run", mock.Anything, 1).Return(2, expectedErr)
+	collector := new(mockMetricsCollector)
+	collector.On("StepStarted", "name").Once()
+	collector.On("StepFinished", "name", mock.AnythingOfType("time.Duration"), expectedErr).Once()
+	step := pipeline.NewMetricsStep[int, int]("name", inner, collector)
+
+	v, err := step.Run(context.Background(), 1)
+
+	assert.Equal(t, 2, v)
+	assert.Equal(t, expectedErr, err)
+	mock.AssertExpectationsForObjects(t, inner, collector)
+}
+
+func TestMetricsStep_GivenAStepToDraw_WhenDrawn_ThenDelegatesToInnerStep(t *testing.T) {
+	mockGraph := new(mockGraph)
+	inner := new(mockStep[int, int])
+	inner.On("Draw", mockGraph).Once()
+	step := pipeline.NewMetricsStep[int, int]("name", inner, new(mockMetricsCollector))
+
+	step.Draw(mockGraph)
+
+	inner.AssertExpectations(t)
+}
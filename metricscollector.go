@@ -0,0 +1,231 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Namespace used for metric names, by default
+	defaultMetricsNamespace = "pipeline"
+	// Content type of the Prometheus text exposition format
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// DefaultMetricsBuckets are the latency histogram buckets (in seconds) used by default
+	DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+type (
+	// MetricsOptions available when collecting metrics
+	MetricsOptions struct {
+		// Namespace prefixed to every metric name, by default "pipeline"
+		Namespace string
+		// Buckets (upper bounds, in seconds) of the latency histogram, by default DefaultMetricsBuckets
+		Buckets []float64
+	}
+
+	// MemoryMetricsCollector is a MetricsCollector that keeps the metrics in memory and exposes them
+	// in the Prometheus text exposition format.
+	//
+	// The following metrics are exposed (prefixed by the namespace), labeled by step (along with the labels of
+	// its metadata, see LabeledMetricsCollector):
+	//   - step_calls_total: counter of step executions
+	//   - step_errors_total: counter of step executions that failed (halt signals aren't failures)
+	//   - step_in_flight: gauge of step executions currently running
+	//   - step_duration_seconds: histogram of step execution latencies
+	MemoryMetricsCollector struct {
+		Options MetricsOptions
+
+		// buckets of the histogram, sorted and without duplicates. Kept apart from the options so changing them
+		// after creating the collector doesn't affect the collected histograms
+		buckets []float64
+
+		mux sync.Mutex
+		// metrics of each series, keyed by their labels
+		steps map[string]*stepMetrics
+	}
+
+	stepMetrics struct {
+		calls    uint64
+		errors   uint64
+		inFlight int64
+		buckets  []uint64
+		sum      float64
+	}
+)
+
+// NewMemoryMetricsCollector creates an in-memory metrics collector as specified
+func NewMemoryMetricsCollector(options MetricsOptions) *MemoryMetricsCollector {
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultMetricsNamespace
+	}
+
+	if len(options.Buckets) == 0 {
+		options.Buckets = DefaultMetricsBuckets
+	}
+	buckets := normalizeBuckets(options.Buckets)
+	options.Buckets = append([]float64(nil), buckets...)
+
+	return &MemoryMetricsCollector{
+		Options: options,
+		buckets: buckets,
+		steps:   make(map[string]*stepMetrics),
+	}
+}
+
+func (c *MemoryMetricsCollector) StepStarted(step string) {
+	c.StepStartedWithLabels(step, nil)
+}
+
+func (c *MemoryMetricsCollector) StepFinished(step string, duration time.Duration, err error) {
+	c.StepFinishedWithLabels(step, nil, duration, err)
+}
+
+func (c *MemoryMetricsCollector) StepStartedWithLabels(step string, labels map[string]string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	m := c.get(step, labels)
+	m.calls++
+	m.inFlight++
+}
+
+func (c *MemoryMetricsCollector) StepFinishedWithLabels(step string, labels map[string]string, duration time.Duration, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	m := c.get(step, labels)
+	m.inFlight--
+	if err != nil && !IsHalt(err) {
+		m.errors++
+	}
+
+	secs := duration.Seconds()
+	m.sum += secs
+	for i, b := range c.buckets {
+		if secs <= b {
+			m.buckets[i]++
+		}
+	}
+}
+
+// WriteTo writes the collected metrics in the Prometheus text exposition format
+func (c *MemoryMetricsCollector) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	c.mux.Lock()
+	series := make([]string, 0, len(c.steps))
+	for s := range c.steps {
+		series = append(series, s)
+	}
+	sort.Strings(series)
+
+	ns := c.Options.Namespace
+	writeMetricFamily(&buf, ns+"_step_calls_total", "counter", "Total number of step executions.", series, func(label string) {
+		fmt.Fprintf(&buf, "%s_step_calls_total{%s} %d\n", ns, label, c.steps[label].calls)
+	})
+	writeMetricFamily(&buf, ns+"_step_errors_total", "counter", "Total number of failed step executions.", series, func(label string) {
+		fmt.Fprintf(&buf, "%s_step_errors_total{%s} %d\n", ns, label, c.steps[label].errors)
+	})
+	writeMetricFamily(&buf, ns+"_step_in_flight", "gauge", "Number of step executions currently running.", series, func(label string) {
+		fmt.Fprintf(&buf, "%s_step_in_flight{%s} %d\n", ns, label, c.steps[label].inFlight)
+	})
+	writeMetricFamily(&buf, ns+"_step_duration_seconds", "histogram", "Latency of step executions in seconds.", series, func(label string) {
+		m := c.steps[label]
+		for i, b := range c.buckets {
+			fmt.Fprintf(&buf, "%s_step_duration_seconds_bucket{%s,le=\"%s\"} %d\n", ns, label, formatFloat(b), m.buckets[i])
+		}
+		count := m.calls - uint64(m.inFlight)
+		fmt.Fprintf(&buf, "%s_step_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", ns, label, count)
+		fmt.Fprintf(&buf, "%s_step_duration_seconds_sum{%s} %s\n", ns, label, formatFloat(m.sum))
+		fmt.Fprintf(&buf, "%s_step_duration_seconds_count{%s} %d\n", ns, label, count)
+	})
+	c.mux.Unlock()
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP exposes the collected metrics in the Prometheus text exposition format, so they can be scraped
+func (c *MemoryMetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	_, _ = c.WriteTo(w)
+}
+
+// get the metrics of the series of the step with the given labels
+func (c *MemoryMetricsCollector) get(step string, labels map[string]string) *stepMetrics {
+	series := formatLabels(step, labels)
+	m, ok := c.steps[series]
+	if !ok {
+		m = &stepMetrics{
+			buckets: make([]uint64, len(c.buckets)),
+		}
+		c.steps[series] = m
+	}
+	return m
+}
+
+// normalizeBuckets returns a sorted copy of the buckets without duplicates. The +Inf bucket is always exposed, so
+// it's dropped too
+func normalizeBuckets(buckets []float64) []float64 {
+	res := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		if !math.IsInf(b, 1) && !math.IsNaN(b) {
+			res = append(res, b)
+		}
+	}
+	sort.Float64s(res)
+
+	unique := res[:0]
+	for _, b := range res {
+		if len(unique) == 0 || b != unique[len(unique)-1] {
+			unique = append(unique, b)
+		}
+	}
+	return unique
+}
+
+func writeMetricFamily(buf *bytes.Buffer, name, kind, help string, series []string, sample func(labels string)) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, kind)
+	for _, s := range series {
+		sample(s)
+	}
+}
+
+// formatLabels of a series in the Prometheus text exposition format, the step followed by the sorted labels.
+// The step label can't be overridden
+func formatLabels(step string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != "step" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "step=\"%s\"", escapeLabelValue(step))
+	for _, k := range keys {
+		fmt.Fprintf(&b, ",%s=\"%s\"", k, escapeLabelValue(labels[k]))
+	}
+	return b.String()
+}
+
+// escapeLabelValue escapes a label value as required by the Prometheus text exposition format
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,102 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestMemoryMetricsCollector_GivenCollectedSteps_WhenWritten_ThenPrometheusTextIsExposed(t *testing.T) {
+	collector := pipeline.NewMemoryMetricsCollector(pipeline.MetricsOptions{
+		Buckets: []float64{1, 0.1, math.Inf(1)},
+	})
+	collector.StepStarted("b")
+	collector.StepFinished("b", 50*time.Millisecond, nil)
+	collector.StepStarted("b")
+	collector.StepFinished("b", 2*time.Second, errors.New("some error"))
+	collector.StepStarted("b")
+	collector.StepFinished("b", 500*time.Millisecond, pipeline.Halt(1))
+	collector.StepStarted("a\"")
+	var buf bytes.Buffer
+
+	_, err := collector.WriteTo(&buf)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `# HELP pipeline_step_calls_total Total number of step executions.
+# TYPE pipeline_step_calls_total counter
+pipeline_step_calls_total{step="a\""} 1
+pipeline_step_calls_total{step="b"} 3
+# HELP pipeline_step_errors_total Total number of failed step executions.
+# TYPE pipeline_step_errors_total counter
+pipeline_step_errors_total{step="a\""} 0
+pipeline_step_errors_total{step="b"} 1
+# HELP pipeline_step_in_flight Number of step executions currently running.
+# TYPE pipeline_step_in_flight gauge
+pipeline_step_in_flight{step="a\""} 1
+pipeline_step_in_flight{step="b"} 0
+# HELP pipeline_step_duration_seconds Latency of step executions in seconds.
+# TYPE pipeline_step_duration_seconds histogram
+pipeline_step_duration_seconds_bucket{step="a\"",le="0.1"} 0
+pipeline_step_duration_seconds_bucket{step="a\"",le="1"} 0
+pipeline_step_duration_seconds_bucket{step="a\"",le="+Inf"} 0
+pipeline_step_duration_seconds_sum{step="a\""} 0
+pipeline_step_duration_seconds_count{step="a\""} 0
+pipeline_step_duration_seconds_bucket{step="b",le="0.1"} 1
+pipeline_step_duration_seconds_bucket{step="b",le="1"} 2
+pipeline_step_duration_seconds_bucket{step="b",le="+Inf"} 3
+pipeline_step_duration_seconds_sum{step="b"} 2.55
+pipeline_step_duration_seconds_count{step="b"} 3
+`, buf.String())
+}
+
+func TestMemoryMetricsCollector_GivenDuplicatedBuckets_WhenChangedAfterCreation_ThenTheyAreNotAffected(t *testing.T) {
+	buckets := []float64{1, 0.1, 1}
+	collector := pipeline.NewMemoryMetricsCollector(pipeline.MetricsOptions{
+		Buckets: buckets,
+	})
+	buckets[0] = 5
+	collector.Options.Buckets = append(collector.Options.Buckets, 10, 20)
+	collector.StepStarted("a")
+	collector.StepFinished("a", 50*time.Millisecond, nil)
+	var buf bytes.Buffer
+
+	_, _ = collector.WriteTo(&buf)
+
+	assert.Contains(t, buf.String(), `pipeline_step_duration_seconds_bucket{step="a",le="0.1"} 1
+pipeline_step_duration_seconds_bucket{step="a",le="1"} 1
+pipeline_step_duration_seconds_bucket{step="a",le="+Inf"} 1
+`)
+}
+
+func TestMemoryMetricsCollector_GivenANamespace_WhenWritten_ThenMetricsArePrefixedWithIt(t *testing.T) {
+	collector := pipeline.NewMemoryMetricsCollector(pipeline.MetricsOptions{
+		Namespace: "custom",
+	})
+	collector.StepStarted("a")
+	var buf bytes.Buffer
+
+	_, _ = collector.WriteTo(&buf)
+
+	assert.Contains(t, buf.String(), "\ncustom_step_calls_total{step=\"a\"} 1\n")
+	assert.Contains(t, buf.String(), "\ncustom_step_duration_seconds_bucket{step=\"a\",le=\"0.005\"} 0\n")
+}
+
+func TestMemoryMetricsCollector_GivenAnHTTPRequest_WhenServed_ThenMetricsAreExposed(t *testing.T) {
+	collector := pipeline.NewMemoryMetricsCollector(pipeline.MetricsOptions{})
+	collector.StepStarted("a")
+	rec := httptest.NewRecorder()
+
+	collector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, rec.Body.String(), "pipeline_step_calls_total{step=\"a\"} 1")
+}
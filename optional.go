@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
 )
 
 type (
@@ -43,10 +44,42 @@ func (c OptionalStep[I, O]) Draw(graph Graph) {
 	)
 }
 
+// Name of the statement of this step
+func (c OptionalStep[I, O]) Name() string {
+	return c.statement.Name()
+}
+
+// Kind of this step, see StepKindOptional
+func (c OptionalStep[I, O]) Kind() StepKind {
+	return StepKindOptional
+}
+
+// Children of this step, the optional one (which may be nil)
+func (c OptionalStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{c.step}
+}
+
+func (c OptionalStep[I, O]) problems() []string {
+	var res []string
+	if !c.statement.evaluable() {
+		res = append(res, fmt.Sprintf("statement %q without evaluation, it always evaluates to false", c.statement.Name()))
+	}
+	if c.step == nil {
+		res = append(res, "nil step")
+	}
+	if c.def == nil {
+		res = append(res, "nil default unit")
+	}
+	return res
+}
+
 // Run a step or skip it depending on the result of a statement evaluation
 func (c OptionalStep[I, O]) Run(ctx context.Context, in I) (O, error) {
-	if c.statement.Evaluate(ctx, in) {
-		return c.step.Run(ctx, in)
+	ok := c.statement.Evaluate(ctx, in)
+	interceptDecision(ctx, c.statement, ok)
+	if ok {
+		res, err := c.step.Run(withStepPath(ctx, c, 0), in)
+		return res, prependStepPath(err, c, 0)
 	}
 	return c.def(ctx, in)
 }
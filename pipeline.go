@@ -157,9 +157,8 @@
 //   renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
 //     Type: pipeline.UMLFormatSVG,
 //   })
-//   file, _ := os.Create("output_file.svg")
 //
 //   step.Draw(graph)
 //
-//   err := renderer.Render(graph, file)
+//   err := pipeline.RenderToFile(ctx, renderer, graph, "output_file.svg")
 package pipeline
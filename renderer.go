@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+type (
+	// RenderableGraph is a graph that can represent itself as text (eg. UML, DOT or Mermaid source)
+	RenderableGraph interface {
+		Graph
+
+		String() string
+	}
+
+	// Renderer allows us to render graphs into an output. Renderers never close the output they write to.
+	//
+	// The UMLRenderer renders UML graphs through a Plant UML server, while the TextRenderer writes the source of
+	// any graph (eg. a DOT or Mermaid one) as it is.
+	Renderer interface {
+		// RenderContext renders the graph, writing it into the output
+		RenderContext(ctx context.Context, graph RenderableGraph, output io.Writer) error
+	}
+
+	// TextRenderer renders graphs by writing their text representation as it is
+	TextRenderer struct{}
+)
+
+// NewTextRenderer creates a renderer that writes the text representation of graphs
+func NewTextRenderer() *TextRenderer {
+	return &TextRenderer{}
+}
+
+// Render writes the text representation of the graph into the output
+func (t *TextRenderer) Render(graph RenderableGraph, output io.Writer) error {
+	return t.RenderContext(context.Background(), graph, output)
+}
+
+// RenderContext writes the text representation of the graph into the output
+func (t *TextRenderer) RenderContext(ctx context.Context, graph RenderableGraph, output io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(output, graph.String())
+	return err
+}
+
+// RenderToFile renders the graph with the renderer into the file at the given path, creating (or truncating) it.
+// The file is closed once rendered, and removed if the rendering fails.
+func RenderToFile(ctx context.Context, renderer Renderer, graph RenderableGraph, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := renderer.RenderContext(ctx, graph, file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
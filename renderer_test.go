@@ -0,0 +1,98 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+type mockRenderer struct {
+	content string
+	err     error
+}
+
+func (m mockRenderer) RenderContext(ctx context.Context, graph pipeline.RenderableGraph, output io.Writer) error {
+	if _, err := io.WriteString(output, m.content); err != nil {
+		return err
+	}
+	return m.err
+}
+
+func TestRenderer_GivenTheRenderers_ThenTheyAreSwappable(t *testing.T) {
+	renderers := []pipeline.Renderer{
+		pipeline.NewUMLRenderer(pipeline.UMLOptions{Type: pipeline.UMLFormatRaw}),
+		pipeline.NewTextRenderer(),
+	}
+
+	for _, renderer := range renderers {
+		var b bytes.Buffer
+		graph := pipeline.NewDOTGraph(pipeline.DOTOptions{})
+		graph.AddActivity("activity")
+
+		err := renderer.RenderContext(context.Background(), graph, &b)
+
+		assert.Nil(t, err)
+		assert.Equal(t, graph.String(), b.String())
+	}
+}
+
+func TestTextRenderer_GivenAGraph_WhenRendered_ThenItsTextIsWritten(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	mockWriteCloser := new(mockWriteCloser)
+	mockWriteCloser.On("Write", []byte("content string")).Return(len("content string"), nil)
+
+	err := pipeline.NewTextRenderer().Render(mockGraph, mockWriteCloser)
+
+	assert.Nil(t, err)
+	mockGraph.AssertExpectations(t)
+	mockWriteCloser.AssertExpectations(t)
+}
+
+func TestTextRenderer_GivenACanceledContext_WhenRendered_ThenContextErrorIsReturned(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := pipeline.NewTextRenderer().RenderContext(ctx, new(mockGraph), new(mockWriteCloser))
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRenderToFile_GivenARenderer_WhenRendered_ThenFileIsWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.svg")
+
+	err := pipeline.RenderToFile(context.Background(), mockRenderer{content: "rendered"}, new(mockGraph), path)
+
+	assert.Nil(t, err)
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "rendered", string(content))
+}
+
+func TestRenderToFile_GivenAFailingRenderer_WhenRendered_ThenErrorIsReturnedAndFileRemoved(t *testing.T) {
+	expectedErr := errors.New("some error")
+	path := filepath.Join(t.TempDir(), "graph.svg")
+
+	err := pipeline.RenderToFile(context.Background(), mockRenderer{content: "partial", err: expectedErr}, new(mockGraph), path)
+
+	assert.Equal(t, expectedErr, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRenderToFile_GivenAnInvalidPath_WhenRendered_ThenErrorIsReturned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "graph.svg")
+
+	err := pipeline.RenderToFile(context.Background(), mockRenderer{}, new(mockGraph), path)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,248 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Colors used for highlighting activities of a run
+	runColorSuccess = "#palegreen"
+	runColorFailure = "#tomato"
+	runColorSkipped = "#lightgrey"
+)
+
+type (
+	// RunRecorder is an interceptor that records which units of a pipeline run (along with their durations and
+	// outcomes) and which branches its decisions took.
+	//
+	// Once a run finishes, the recording can be drawn through a RunUMLGraph to highlight the path it took.
+	//
+	// Graphs identify activities by label, so activities are recorded by the name of their unit and decisions by the
+	// name of their statement. Different units sharing a name (or a statement evaluated by different steps) share a
+	// single record, and are highlighted alike. Validate reports units sharing a name.
+	//
+	//   recorder := pipeline.NewRunRecorder()
+	//   _, err := step.Run(pipeline.WithInterceptors(ctx, recorder), in)
+	//
+	//   graph := pipeline.NewRunUMLGraph(recorder)
+	//   step.Draw(graph)
+	RunRecorder struct {
+		mux        sync.Mutex
+		activities map[string]*ActivityRecord
+		decisions  map[string]*DecisionRecord
+	}
+
+	// ActivityRecord is the recording of the executions of an activity (a unit identified by its name)
+	ActivityRecord struct {
+		// Name of the activity
+		Name string
+		// Runs is the amount of times the activity ran
+		Runs int
+		// Failures is the amount of times the activity failed (halt signals aren't failures)
+		Failures int
+		// Duration is the total time spent running the activity
+		Duration time.Duration
+		// Err is the last error the activity failed with, if any
+		Err error
+	}
+
+	// DecisionRecord is the recording of the evaluations of a statement (identified by its name)
+	DecisionRecord struct {
+		// Statement name
+		Statement string
+		// Yes is the amount of times the statement held true
+		Yes int
+		// No is the amount of times the statement held false
+		No int
+	}
+
+	// RunUMLGraph is an UML graph that highlights the path a recorded run took.
+	//
+	// Activities that ran are colored green (or red if they failed) and annotated with their timings,
+	// while activities that didn't run are colored grey. Decision branches that were taken are marked as such.
+	RunUMLGraph struct {
+		uml      *UMLGraph
+		recorder *RunRecorder
+	}
+
+	runRecorderStartContextKey struct{}
+)
+
+// NewRunRecorder creates an empty run recorder
+func NewRunRecorder() *RunRecorder {
+	return &RunRecorder{
+		activities: make(map[string]*ActivityRecord),
+		decisions:  make(map[string]*DecisionRecord),
+	}
+}
+
+func (r *RunRecorder) Before(ctx context.Context, _ StepCall) context.Context {
+	return context.WithValue(ctx, runRecorderStartContextKey{}, time.Now())
+}
+
+func (r *RunRecorder) After(ctx context.Context, call StepCall) {
+	start, _ := ctx.Value(runRecorderStartContextKey{}).(time.Time)
+	duration := time.Since(start)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	a, ok := r.activities[call.Name]
+	if !ok {
+		a = &ActivityRecord{Name: call.Name}
+		r.activities[call.Name] = a
+	}
+	a.Runs++
+	a.Duration += duration
+	if call.Err != nil && !IsHalt(call.Err) {
+		a.Failures++
+		a.Err = call.Err
+	}
+}
+
+func (r *RunRecorder) Decided(_ context.Context, statement string, result bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	d, ok := r.decisions[statement]
+	if !ok {
+		d = &DecisionRecord{Statement: statement}
+		r.decisions[statement] = d
+	}
+	if result {
+		d.Yes++
+	} else {
+		d.No++
+	}
+}
+
+// Activity returns the recording of the named activity, or false if it never ran
+func (r *RunRecorder) Activity(name string) (ActivityRecord, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if a, ok := r.activities[name]; ok {
+		return *a, true
+	}
+	return ActivityRecord{}, false
+}
+
+// Activities returns the recording of every activity that ran, sorted by name
+func (r *RunRecorder) Activities() []ActivityRecord {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	res := make([]ActivityRecord, 0, len(r.activities))
+	for _, a := range r.activities {
+		res = append(res, *a)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// Decision returns the recording of the named statement, or false if it was never evaluated
+func (r *RunRecorder) Decision(statement string) (DecisionRecord, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if d, ok := r.decisions[statement]; ok {
+		return *d, true
+	}
+	return DecisionRecord{}, false
+}
+
+// NewRunUMLGraph creates an UML activity graph diagram that highlights the run recorded
+func NewRunUMLGraph(recorder *RunRecorder) *RunUMLGraph {
+	return &RunUMLGraph{
+		uml:      NewUMLGraph(),
+		recorder: recorder,
+	}
+}
+
+func (g *RunUMLGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	yesLabel, noLabel := "yes", "no"
+	if d, ok := g.recorder.Decision(statement); ok {
+		if d.Yes > 0 {
+			yesLabel = "yes, taken"
+		}
+		if d.No > 0 {
+			noLabel = "no, taken"
+		}
+	}
+	g.uml.decision(statement, yesLabel, noLabel, func() { yes(g) }, func() { no(g) })
+}
+
+func (g *RunUMLGraph) AddConcurrency(forks ...GraphDrawer) {
+	g.uml.concurrency(len(forks), func(i int) { forks[i](g) })
+}
+
+func (g *RunUMLGraph) AddActivity(label string) {
+	g.AddStyledActivity(label, ActivityStyle{})
+}
+
+// AddStyledActivity keeps the stereotypes of the style, but colors the activity by the outcome of its run
+func (g *RunUMLGraph) AddStyledActivity(label string, style ActivityStyle) {
+	style = g.uml.stereotyped(style)
+
+	a, ok := g.recorder.Activity(label)
+	if !ok {
+		style.Color = runColorSkipped
+		g.uml.activity(escapeUML(label), style)
+		return
+	}
+
+	style.Color = runColorSuccess
+	if a.Failures > 0 {
+		style.Color = runColorFailure
+	}
+
+	timing := a.Duration.String()
+	if a.Runs > 1 {
+		timing = fmt.Sprintf("%d runs, %s", a.Runs, timing)
+	}
+	g.uml.activity(fmt.Sprintf("%s\\n<size:10>%s</size>", escapeUML(label), timing), style)
+}
+
+func (g *RunUMLGraph) AddDescribedActivity(label string, style ActivityStyle, metadata StepMetadata) {
+	g.uml.swimlane(metadata.Owner)
+	g.AddStyledActivity(label, style)
+	g.uml.describe(metadata)
+}
+
+func (g *RunUMLGraph) AddLoop(condition string, body GraphDrawer) {
+	g.uml.loop(condition, func() { body(g) })
+}
+
+func (g *RunUMLGraph) AddSwitch(statement string, cases ...GraphCase) {
+	labels := make([]string, len(cases))
+	for i, c := range cases {
+		labels[i] = c.Label
+	}
+	g.uml.switchCases(statement, labels, func(i int) { cases[i].Draw(g) })
+}
+
+func (g *RunUMLGraph) AddNote(text string) {
+	g.uml.note(text)
+}
+
+func (g *RunUMLGraph) AddErrorHandler(label string, body GraphDrawer, handler GraphDrawer) {
+	g.uml.errorHandler(label, func() { body(g) }, func() { handler(g) })
+}
+
+func (g *RunUMLGraph) AddPartition(name string, body GraphDrawer) {
+	g.uml.partition(name, func() { body(g) })
+}
+
+func (g *RunUMLGraph) AddStereotyped(stereotype string, body GraphDrawer) {
+	g.uml.stereotype(stereotype, func() { body(g) })
+}
+
+func (g *RunUMLGraph) String() string {
+	return g.uml.String()
+}
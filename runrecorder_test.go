@@ -0,0 +1,118 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func newRecordedPipeline(failing error) pipeline.Step[int, int] {
+	unit := func(name string, err error) pipeline.Step[int, int] {
+		return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+			return i, err
+		})
+	}
+	isEven := pipeline.NewStatement("is_even", func(ctx context.Context, i int) bool {
+		return i%2 == 0
+	})
+	return pipeline.NewSequentialStep[int, int, int](
+		unit("start", nil),
+		pipeline.NewConditionalStep[int, int](isEven, unit("even", nil), unit("odd", failing)),
+	)
+}
+
+func TestRunRecorder_GivenARun_WhenRecorded_ThenActivitiesAndDecisionsAreKept(t *testing.T) {
+	expectedErr := errors.New("some error")
+	recorder := pipeline.NewRunRecorder()
+	step := newRecordedPipeline(expectedErr)
+
+	_, err := step.Run(pipeline.WithInterceptors(context.Background(), recorder), 1)
+
+	assert.NotNil(t, err)
+	activities := recorder.Activities()
+	assert.Len(t, activities, 2)
+	assert.Equal(t, "odd", activities[0].Name)
+	assert.Equal(t, 1, activities[0].Failures)
+	assert.Equal(t, expectedErr, activities[0].Err)
+	assert.Equal(t, "start", activities[1].Name)
+	assert.Equal(t, 1, activities[1].Runs)
+	assert.Zero(t, activities[1].Failures)
+	_, ok := recorder.Activity("even")
+	assert.False(t, ok)
+	d, ok := recorder.Decision("is_even")
+	assert.True(t, ok)
+	assert.Equal(t, pipeline.DecisionRecord{Statement: "is_even", No: 1}, d)
+	_, ok = recorder.Decision("unknown")
+	assert.False(t, ok)
+}
+
+func TestRunRecorder_GivenAnOptionalStep_WhenRecorded_ThenDecisionIsKept(t *testing.T) {
+	recorder := pipeline.NewRunRecorder()
+	stmt := pipeline.NewStatement("stmt", func(ctx context.Context, i int) bool {
+		return i > 0
+	})
+	step := pipeline.NewOptionalStep[int](stmt, pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}))
+	ctx := pipeline.WithInterceptors(context.Background(), recorder)
+
+	_, _ = step.Run(ctx, 1)
+	_, _ = step.Run(ctx, 1)
+	_, _ = step.Run(ctx, 0)
+
+	d, _ := recorder.Decision("stmt")
+	a, _ := recorder.Activity("unit")
+	assert.Equal(t, pipeline.DecisionRecord{Statement: "stmt", Yes: 2, No: 1}, d)
+	assert.Equal(t, 2, a.Runs)
+}
+
+func TestRunUMLGraph_GivenARecordedRun_WhenDrawn_ThenExecutedPathIsHighlighted(t *testing.T) {
+	recorder := pipeline.NewRunRecorder()
+	step := newRecordedPipeline(errors.New("some error"))
+	_, _ = step.Run(pipeline.WithInterceptors(context.Background(), recorder), 1)
+	graph := pipeline.NewRunUMLGraph(recorder)
+	validator := regexp.MustCompile(`^@startuml
+start
+#palegreen:start\\n<size:10>[.\d]+[µnm]?s</size>; <<unit>>
+if \(is_even\) then \(yes\)
+#lightgrey:even; <<unit>>
+else \(no, taken\)
+#tomato:odd\\n<size:10>[.\d]+[µnm]?s</size>; <<unit>>
+endif
+stop
+@enduml
+$`)
+
+	step.Draw(graph)
+
+	assert.Regexp(t, validator, graph.String())
+}
+
+func TestRunUMLGraph_GivenARecordedRun_WhenDrawingConcurrency_ThenBranchesAreHighlighted(t *testing.T) {
+	recorder := pipeline.NewRunRecorder()
+	unit := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+	step := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{unit, unit}, func(ctx context.Context, a, b int) (int, error) {
+		return a + b, nil
+	})
+	_, _ = step.Run(pipeline.WithInterceptors(context.Background(), recorder), 1)
+	graph := pipeline.NewRunUMLGraph(recorder)
+
+	step.Draw(graph)
+
+	assert.Regexp(t, regexp.MustCompile(`fork\n#palegreen:unit\\n<size:10>2 runs, [.\d]+[µnm]?s</size>; <<unit>>\nfork again\n#palegreen:unit`), graph.String())
+}
+
+func TestRunUMLGraph_GivenAStyledActivity_WhenDrawn_ThenStereotypesAreKeptAndColorIsTheOutcome(t *testing.T) {
+	graph := pipeline.NewRunUMLGraph(pipeline.NewRunRecorder())
+
+	graph.AddStyledActivity("not (run)", pipeline.ActivityStyle{Color: "#blue", Stereotypes: []string{"traced"}})
+
+	assert.Contains(t, graph.String(), "\n#lightgrey:not <U+0028>run<U+0029>; <<traced>>\n")
+}
@@ -28,14 +28,36 @@ func NewSequentialStep[I, M, O any](s Step[I, M], e Step[M, O]) SequentialStep[I
 	}
 }
 
+// Kind of this step, see StepKindSequential
+func (s SequentialStep[I, M, O]) Kind() StepKind {
+	return StepKindSequential
+}
+
+// Children of this step, the start and end ones
+func (s SequentialStep[I, M, O]) Children() []DrawableGraph {
+	return []DrawableGraph{s.start, s.end}
+}
+
+func (s SequentialStep[I, M, O]) problems() []string {
+	var res []string
+	if s.start == nil {
+		res = append(res, "nil start step")
+	}
+	if s.end == nil {
+		res = append(res, "nil end step")
+	}
+	return res
+}
+
 // Run both steps sequentially. If one of them fails, the step is halted and the error is returned.
 func (s SequentialStep[I, M, O]) Run(ctx context.Context, in I) (O, error) {
-	m, err := s.start.Run(ctx, in)
+	m, err := s.start.Run(withStepPath(ctx, s, 0), in)
 	if err != nil {
-		return *new(O), err
+		return *new(O), prependStepPath(err, s, 0)
 	}
 
-	return s.end.Run(ctx, m)
+	res, err := s.end.Run(withStepPath(ctx, s, 1), m)
+	return res, prependStepPath(err, s, 1)
 }
 
 func (s SequentialStep[I, M, O]) Draw(graph Graph) {
@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// SpanOutcomeSuccess is the outcome of a span that ended without an error
+	SpanOutcomeSuccess = "success"
+	// SpanOutcomeFailure is the outcome of a span that ended with an error
+	SpanOutcomeFailure = "failure"
+	// SpanOutcomeHalted is the outcome of a span that ended with a halt signal (see Halt)
+	SpanOutcomeHalted = "halted"
+)
+
+type (
+	// SlogTracer is a tracer that emits a structured record through a slog.Logger for each span once it ends.
+	//
+	// Each record carries the following attributes:
+	//   - step: name of the span
+	//   - start: start timestamp of the span, formatted as RFC3339Nano
+	//   - duration_ms: duration of the span in milliseconds
+	//   - outcome: one of SpanOutcomeSuccess, SpanOutcomeFailure or SpanOutcomeHalted
+	//   - error: the error string, only present on failures
+	//
+	// Followed by the attributes added through the context (see WithTraceAttributes) and the ones set in the span.
+	//
+	// As it relies on log/slog, this tracer is the reason the package requires Go 1.21 or later.
+	SlogTracer struct {
+		logger *slog.Logger
+	}
+
+	slogSpan struct {
+		ctx    context.Context
+		logger *slog.Logger
+		name   string
+		start  time.Time
+
+		mux   sync.Mutex
+		attrs []Attribute
+		err   error
+	}
+
+	traceAttributesContextKey struct{}
+)
+
+// NewSlogTracer creates a tracer that will log each span as a structured record through the given logger
+func NewSlogTracer(logger *slog.Logger) *SlogTracer {
+	return &SlogTracer{
+		logger: logger,
+	}
+}
+
+// NewJSONTracer creates a tracer that will write each span as a JSON line into the writer
+func NewJSONTracer(writer io.Writer) *SlogTracer {
+	return NewSlogTracer(slog.New(slog.NewJSONHandler(writer, nil)))
+}
+
+// WithTraceAttributes returns a copy of the context carrying the given attributes (along with the ones
+// the context already had). Tracers supporting them will add them to every span started with it.
+func WithTraceAttributes(ctx context.Context, attrs ...Attribute) context.Context {
+	prev := TraceAttributesFromContext(ctx)
+	all := make([]Attribute, 0, len(prev)+len(attrs))
+	all = append(all, prev...)
+	all = append(all, attrs...)
+	return context.WithValue(ctx, traceAttributesContextKey{}, all)
+}
+
+// TraceAttributesFromContext returns the attributes added to the context through WithTraceAttributes
+func TraceAttributesFromContext(ctx context.Context) []Attribute {
+	attrs, _ := ctx.Value(traceAttributesContextKey{}).([]Attribute)
+	return attrs
+}
+
+func (t *SlogTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &slogSpan{
+		ctx:    ctx,
+		logger: t.logger,
+		name:   name,
+		start:  time.Now(),
+		attrs:  append([]Attribute(nil), TraceAttributesFromContext(ctx)...),
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+func (s *slogSpan) SetAttributes(attrs ...Attribute) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *slogSpan) RecordError(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.err = err
+}
+
+func (s *slogSpan) End() {
+	duration := time.Since(s.start)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	level := slog.LevelInfo
+	outcome := SpanOutcomeSuccess
+	if s.err != nil {
+		if IsHalt(s.err) {
+			outcome = SpanOutcomeHalted
+		} else {
+			level = slog.LevelError
+			outcome = SpanOutcomeFailure
+		}
+	}
+
+	attrs := make([]slog.Attr, 0, 5+len(s.attrs))
+	attrs = append(attrs,
+		slog.String("step", s.name),
+		slog.String("start", s.start.Format(time.RFC3339Nano)),
+		slog.Float64("duration_ms", float64(duration)/float64(time.Millisecond)),
+		slog.String("outcome", outcome),
+	)
+	if outcome == SpanOutcomeFailure {
+		attrs = append(attrs, slog.String("error", s.err.Error()))
+	}
+	for _, a := range s.attrs {
+		attrs = append(attrs, slog.Any(a.Key, a.Value))
+	}
+
+	s.logger.LogAttrs(s.ctx, level, "step finished", attrs...)
+}
@@ -0,0 +1,89 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestSlogTracer_GivenASuccessfulTracedStep_WhenRun_ThenAJSONRecordIsWritten(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	mockStep := new(mockStep[any, any])
+	mockStep.On("Run", mock.Anything, 1).Return("test", nil)
+	step := pipeline.NewTracedStepWithTracer[any, any]("test name", mockStep, pipeline.NewJSONTracer(writer))
+	ctx := pipeline.WithTraceAttributes(context.Background(), pipeline.NewAttribute("run_id", "1234"))
+
+	_, _ = step.Run(ctx, 1)
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal(writer.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "test name", record["step"])
+	assert.Equal(t, pipeline.SpanOutcomeSuccess, record["outcome"])
+	assert.Equal(t, "1234", record["run_id"])
+	assert.IsType(t, float64(0), record["duration_ms"])
+	assert.NotContains(t, record, "error")
+	_, err := time.Parse(time.RFC3339Nano, record["start"].(string))
+	assert.Nil(t, err)
+}
+
+func TestSlogTracer_GivenAFailingTracedStep_WhenRun_ThenErrorRecordIsWritten(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	mockStep := new(mockStep[any, any])
+	mockStep.On("Run", mock.Anything, 1).Return(nil, errors.New("some error"))
+	step := pipeline.NewTracedStepWithTracer[any, any]("test name", mockStep, pipeline.NewJSONTracer(writer))
+
+	_, _ = step.Run(context.Background(), 1)
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal(writer.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, pipeline.SpanOutcomeFailure, record["outcome"])
+	assert.Equal(t, "some error", record["error"])
+}
+
+func TestSlogTracer_GivenAHaltingTracedStep_WhenRun_ThenHaltedRecordIsWritten(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	mockStep := new(mockStep[any, any])
+	mockStep.On("Run", mock.Anything, 1).Return(nil, pipeline.Halt(1))
+	step := pipeline.NewTracedStepWithTracer[any, any]("test name", mockStep, pipeline.NewJSONTracer(writer))
+
+	_, _ = step.Run(context.Background(), 1)
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal(writer.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, pipeline.SpanOutcomeHalted, record["outcome"])
+	assert.NotContains(t, record, "error")
+}
+
+func TestSlogTracer_GivenALogger_WhenSpanEnds_ThenSpanAttributesAreLogged(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	tracer := pipeline.NewSlogTracer(slog.New(slog.NewTextHandler(writer, nil)))
+
+	_, span := tracer.Start(context.Background(), "test name")
+	span.SetAttributes(pipeline.NewAttribute("key", "value"))
+	span.End()
+
+	assert.True(t, strings.Contains(writer.String(), "step=\"test name\""))
+	assert.True(t, strings.Contains(writer.String(), "key=value"))
+}
+
+func TestWithTraceAttributes_GivenNestedCalls_ThenAttributesAreAccumulated(t *testing.T) {
+	ctx := pipeline.WithTraceAttributes(context.Background(), pipeline.NewAttribute("a", 1))
+	ctx = pipeline.WithTraceAttributes(ctx, pipeline.NewAttribute("b", 2))
+
+	attrs := pipeline.TraceAttributesFromContext(ctx)
+
+	assert.Equal(t, []pipeline.Attribute{pipeline.NewAttribute("a", 1), pipeline.NewAttribute("b", 2)}, attrs)
+}
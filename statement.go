@@ -27,6 +27,11 @@ func (s Statement[T]) Name() string {
 	return s.label
 }
 
+// evaluable reports whether the statement has an evaluation, else it always evaluates to false
+func (s Statement[T]) evaluable() bool {
+	return s.fn != nil
+}
+
 func (s Statement[T]) Evaluate(ctx context.Context, v T) bool {
 	return s.fn != nil && s.fn(ctx, v)
 }
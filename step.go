@@ -12,9 +12,10 @@ type (
 
 	// UnitStep for making a unit of work.
 	UnitStep[I, O any] struct {
-		id   string
-		name string
-		fn   Unit[I, O]
+		id       string
+		name     string
+		fn       Unit[I, O]
+		metadata StepMetadata
 	}
 
 	// Step is runnable element that yields a result or error from a given input
@@ -50,16 +51,58 @@ func (s UnitStep[I, O]) Name() string {
 	return s.name
 }
 
+// Metadata describing this step, see WithMetadata
+func (s UnitStep[I, O]) Metadata() StepMetadata {
+	return s.metadata
+}
+
+// WithMetadata returns a copy of this step (keeping its ID) described by the given metadata
+func (s UnitStep[I, O]) WithMetadata(metadata StepMetadata) UnitStep[I, O] {
+	s.metadata = metadata
+	return s
+}
+
+// Kind of this step, see StepKindUnit
+func (s UnitStep[I, O]) Kind() StepKind {
+	return StepKindUnit
+}
+
+// Children of this step, a unit has none
+func (s UnitStep[I, O]) Children() []DrawableGraph {
+	return nil
+}
+
+func (s UnitStep[I, O]) problems() []string {
+	if s.fn == nil {
+		return []string{"nil unit function"}
+	}
+	return nil
+}
+
 // Run a step and yield a result of type O or an error if it failed.
-// This operation is context-aware.
+// This operation is context-aware, running the interceptors the context carries (see WithInterceptors).
+// Errors are wrapped into a StepError identifying this step.
 func (s UnitStep[I, O]) Run(ctx context.Context, in I) (O, error) {
 	if err := ctx.Err(); err != nil {
-		return *new(O), err
+		return *new(O), newStepError(s.id, s.name, err)
 	}
-	return s.fn(ctx, in)
+
+	var res O
+	var err error
+	if interceptors := interceptorsFromContext(ctx); len(interceptors) > 0 {
+		res, err = intercept(ctx, interceptors, StepCall{ID: s.id, Name: s.name, Metadata: s.metadata, Input: in}, s.fn, in)
+	} else {
+		res, err = s.fn(ctx, in)
+	}
+	return res, newStepError(s.id, s.name, err)
 }
 
-// Draw this step in a graph
+// Draw this step in a graph as an activity stereotyped as a unit, described by its metadata if it has any
 func (s UnitStep[I, O]) Draw(graph Graph) {
-	graph.AddActivity(s.Name())
+	style := ActivityStyle{Stereotypes: []string{"unit"}}
+	if s.metadata.IsZero() {
+		DrawStyledActivity(graph, s.Name(), style)
+		return
+	}
+	DrawDescribedActivity(graph, s.Name(), style, s.metadata)
 }
@@ -158,7 +158,7 @@ func TestUnitStep_GivenARunFuncThatErrors_WhenRunning_ThenErrorIsReturned(t *tes
 
 	_, err := step.Run(context.Background(), 1)
 
-	assert.Equal(t, expectedErr, err)
+	assert.ErrorIs(t, err, expectedErr)
 }
 
 func TestUnitStep_GivenOne_ThenHasID(t *testing.T) {
@@ -0,0 +1,117 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type (
+	// StepError is an error raised by a step, that records which step failed and the path to it
+	// from the step that was run.
+	//
+	// Errors are wrapped by UnitStep and their path is extended as they propagate through the composite
+	// steps of the API, eg. sequential/1/concurrent/1/conditional:is_close/true/notify_driver_close
+	//
+	// Paths are the ones Walk yields (see Walk), so the failing step can be looked up with FindByPath.
+	// Custom steps that don't implement InspectableStep don't extend them.
+	//
+	// The original error can be retrieved through errors.Is / errors.As.
+	StepError struct {
+		// Name of the step that failed
+		Name string
+		// ID of the step that failed
+		ID string
+		// Err the step failed with. If a custom step wrapped the StepError it propagated through, Err is the wrapping
+		// error instead, so it can still be retrieved through errors.Is / errors.As.
+		Err error
+
+		path []string
+		// message of the error, when it differs from the one of Err (eg. as a custom step wrapped it)
+		message string
+	}
+)
+
+// Error returns the path of the failing step along with the original error
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path(), e.cause())
+}
+
+// Unwrap returns the original error
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Path returns the path from the step that was run to the failing step, separated by slashes
+func (e *StepError) Path() string {
+	return strings.Join(e.path, "/")
+}
+
+// Segments returns each segment of the path, as walked by Walk
+func (e *StepError) Segments() []string {
+	return append([]string(nil), e.path...)
+}
+
+// FailingStep returns the innermost StepError the error wraps, that is, the step that originally failed.
+// Returns false if the error doesn't wrap a StepError.
+func FailingStep(err error) (*StepError, bool) {
+	var res *StepError
+	for {
+		var se *StepError
+		if !errors.As(err, &se) {
+			break
+		}
+		// wrapped errors of the same step are the same failure with a shorter path, keep the outermost one
+		if res == nil || res.ID != se.ID {
+			res = se
+		}
+		err = se.Err
+	}
+	return res, res != nil
+}
+
+// cause of the error, that is, its message without the path
+func (e *StepError) cause() string {
+	if len(e.message) > 0 {
+		return e.message
+	}
+	return e.Err.Error()
+}
+
+// newStepError wraps the error of a failing step. Halt signals aren't failures, so they aren't wrapped.
+func newStepError(id, name string, err error) error {
+	if err == nil || IsHalt(err) {
+		return err
+	}
+	return &StepError{
+		Name: name,
+		ID:   id,
+		Err:  err,
+		path: []string{name}, // the segment of a unit is its name
+	}
+}
+
+// prependStepPath extends the path of the error returned by the i-th child of the step, as it propagates through
+// it. Errors that don't wrap a StepError (eg. returned by custom steps) are returned as they are.
+//
+// If the StepError is wrapped (eg. by a custom step adding context to it), the wrapping error is kept as the one
+// the step failed with, only moving the path of the StepError in front of its message.
+func prependStepPath(err error, step DrawableGraph, i int) error {
+	var se *StepError
+	if err == nil || !errors.As(err, &se) {
+		return err
+	}
+
+	res := &StepError{
+		Name:    se.Name,
+		ID:      se.ID,
+		Err:     se.Err,
+		path:    append(stepSegments(step, i), se.path...),
+		message: se.message,
+	}
+	if err != error(se) {
+		res.Err = err
+		res.message = strings.Replace(err.Error(), se.Error(), se.cause(), 1)
+	}
+	return res
+}
@@ -0,0 +1,198 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+// The following example shows how to know which step of a pipeline failed
+// through the path recorded in the error.
+func ExampleFailingStep() {
+	getDriver := pipeline.NewUnitStep("get_driver", func(ctx context.Context, id int) (int, error) {
+		return id, nil
+	})
+	getLocation := pipeline.NewUnitStep("get_location", func(ctx context.Context, id int) (int, error) {
+		return 0, context.DeadlineExceeded
+	})
+	pipe := pipeline.NewConcurrentStep([]pipeline.Step[int, int]{getDriver, getLocation}, func(ctx context.Context, a, b int) (int, error) {
+		return a + b, nil
+	})
+
+	_, err := pipe.Run(context.Background(), 1)
+
+	step, _ := pipeline.FailingStep(err)
+	fmt.Println(step.Name)
+	fmt.Println(step.Path())
+	fmt.Println(errors.Is(err, context.DeadlineExceeded))
+	// output:
+	// get_location
+	// concurrent/1/get_location
+	// true
+}
+
+func TestStepError_GivenANestedFailingUnit_WhenRun_ThenErrorHasFullPath(t *testing.T) {
+	expectedErr := errors.New("some error")
+	unit := func(name string, err error) pipeline.UnitStep[int, int] {
+		return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+			return i, err
+		})
+	}
+	isClose := pipeline.NewStatement("is_close", func(ctx context.Context, i int) bool {
+		return true
+	})
+	shouldNotify := pipeline.NewStatement("should_notify", func(ctx context.Context, i int) bool {
+		return true
+	})
+	failing := unit("notify_driver_close", expectedErr)
+	step := pipeline.NewSequentialStep[int, int, int](
+		unit("start", nil),
+		pipeline.NewConcurrentStep([]pipeline.Step[int, int]{
+			unit("get_driver", nil),
+			pipeline.NewConditionalStep[int, int](
+				isClose,
+				pipeline.NewOptionalStep[int](shouldNotify, failing),
+				unit("notify_driver_far", nil),
+			),
+		}, func(ctx context.Context, a, b int) (int, error) {
+			return a, nil
+		}),
+	)
+
+	_, err := step.Run(context.Background(), 1)
+
+	var se *pipeline.StepError
+	assert.ErrorAs(t, err, &se)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, "notify_driver_close", se.Name)
+	assert.Equal(t, failing.ID(), se.ID)
+	assert.Equal(t, "sequential/1/concurrent/1/conditional:is_close/true/optional:should_notify/notify_driver_close", se.Path())
+	assert.Equal(t, []string{"sequential", "1", "concurrent", "1", "conditional:is_close", "true", "optional:should_notify", "notify_driver_close"}, se.Segments())
+	assert.Equal(t, "sequential/1/concurrent/1/conditional:is_close/true/optional:should_notify/notify_driver_close: some error", err.Error())
+
+	found, ok := pipeline.FindByPath(step, se.Segments())
+	assert.True(t, ok)
+	assert.Equal(t, failing.ID(), found.(pipeline.UnitStep[int, int]).ID())
+	assert.Len(t, pipeline.FindByName(step, se.Name), 1)
+}
+
+func TestStepError_GivenAWrappedStepError_WhenItPropagates_ThenItsPathIsExtendedKeepingTheMessage(t *testing.T) {
+	expectedErr := errors.New("some error")
+	failing := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, expectedErr
+	})
+	stmt := pipeline.NewStatement("stmt", func(ctx context.Context, i int) bool {
+		return true
+	})
+	step := pipeline.NewOptionalStep[int](stmt, pipeline.NewOptionalStep[int](stmt, wrappingStep[int]{step: failing}))
+
+	_, err := step.Run(context.Background(), 1)
+
+	se, ok := pipeline.FailingStep(err)
+	assert.True(t, ok)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, "optional:stmt/optional:stmt/unit", se.Path())
+	assert.Equal(t, "optional:stmt/optional:stmt/unit: wrapped: some error", err.Error())
+}
+
+func TestStepError_GivenAWrappedStepError_WhenItPropagates_ThenTheWrappingErrorCanBeRetrieved(t *testing.T) {
+	failing := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, errors.New("some error")
+	})
+	stmt := pipeline.NewStatement("stmt", func(ctx context.Context, i int) bool {
+		return true
+	})
+	step := pipeline.NewOptionalStep[int](stmt, wrappingStep[int]{step: failing})
+
+	_, err := step.Run(context.Background(), 1)
+
+	var we *wrappingError
+	assert.ErrorAs(t, err, &we)
+	se, ok := pipeline.FailingStep(err)
+	assert.True(t, ok)
+	assert.Equal(t, "optional:stmt/unit", se.Path())
+	assert.Equal(t, we, se.Err)
+}
+
+func TestStepError_GivenAnExpiredContext_WhenRun_ThenErrorIdentifiesTheStep(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	step := pipeline.NewUnitStep("get_driver", func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+
+	_, err := step.Run(ctx, 1)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "get_driver: context deadline exceeded", err.Error())
+}
+
+func TestStepError_GivenAHalt_WhenRun_ThenItIsNotWrapped(t *testing.T) {
+	halt := pipeline.Halt(1)
+	step := pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) {
+		return i, halt
+	})
+
+	_, err := step.Run(context.Background(), 1)
+
+	assert.Equal(t, halt, err)
+}
+
+func TestFailingStep_GivenNestedPipelines_ThenInnermostStepIsReturned(t *testing.T) {
+	expectedErr := errors.New("some error")
+	inner := pipeline.NewUnitStep("inner", func(ctx context.Context, i int) (int, error) {
+		return i, expectedErr
+	})
+	outer := pipeline.NewUnitStep("outer", func(ctx context.Context, i int) (int, error) {
+		return inner.Run(ctx, i)
+	})
+
+	_, err := outer.Run(context.Background(), 1)
+
+	se, ok := pipeline.FailingStep(err)
+	assert.True(t, ok)
+	assert.Equal(t, "inner", se.Name)
+	assert.Equal(t, expectedErr, se.Err)
+}
+
+func TestFailingStep_GivenAnErrorWithoutSteps_ThenFalseIsReturned(t *testing.T) {
+	se, ok := pipeline.FailingStep(errors.New("some error"))
+
+	assert.False(t, ok)
+	assert.Nil(t, se)
+}
+
+type wrappingStep[T any] struct {
+	step pipeline.Step[T, T]
+}
+
+func (w wrappingStep[T]) Draw(graph pipeline.Graph) {
+	w.step.Draw(graph)
+}
+
+func (w wrappingStep[T]) Run(ctx context.Context, in T) (T, error) {
+	res, err := w.step.Run(ctx, in)
+	if err != nil {
+		return res, &wrappingError{err: err}
+	}
+	return res, nil
+}
+
+type wrappingError struct {
+	err error
+}
+
+func (w *wrappingError) Error() string {
+	return fmt.Sprintf("wrapped: %s", w.err)
+}
+
+func (w *wrappingError) Unwrap() error {
+	return w.err
+}
@@ -0,0 +1,311 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// Sizes (in pixels) used for laying out svg graphs
+	svgMargin       = 20.0
+	svgCharWidth    = 7.0
+	svgFontSize     = 12.0
+	svgTextPadding  = 12.0
+	svgMinBoxWidth  = 60.0
+	svgBoxHeight    = 32.0
+	svgDiamondExtra = 40.0
+	svgDiamondH     = 40.0
+	svgMergeSize    = 16.0
+	svgBarHeight    = 6.0
+	svgTerminalR    = 10.0
+	svgArrowGap     = 24.0
+	svgBranchGap    = 30.0
+	svgMinColumn    = 40.0
+)
+
+type (
+	// SVGGraph represents a graph that lays itself out and renders into an SVG image, without
+	// needing any external tool or network access.
+	//
+	// Activities are drawn as rounded boxes in sequence, decisions as diamonds whose yes/no branches
+	// are laid out side by side (merging back into a smaller diamond), and concurrencies as fork/join
+	// bars with their branches laid out side by side.
+	SVGGraph struct {
+		root *svgSequence
+		// current is the sequence being drawn
+		current *svgSequence
+	}
+
+	// svgNode is an element of the svg graph that can be measured and drawn.
+	// Nodes are entered through their top-center and exited through their bottom-center.
+	svgNode interface {
+		size() (w, h float64)
+		// draw the node with its top-left corner in the given coordinates
+		draw(sb *strings.Builder, x, y float64)
+	}
+
+	svgSequence struct {
+		nodes []svgNode
+	}
+
+	svgActivity struct {
+		label string
+	}
+
+	svgDecision struct {
+		statement string
+		yes, no   *svgSequence
+	}
+
+	svgFork struct {
+		branches []*svgSequence
+	}
+)
+
+// NewSVGGraph creates an SVG activity diagram that represents one
+func NewSVGGraph() *SVGGraph {
+	root := &svgSequence{}
+	return &SVGGraph{
+		root:    root,
+		current: root,
+	}
+}
+
+func (g *SVGGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	d := &svgDecision{
+		statement: statement,
+		yes:       &svgSequence{},
+		no:        &svgSequence{},
+	}
+	g.current.nodes = append(g.current.nodes, d)
+
+	g.within(d.yes, yes)
+	g.within(d.no, no)
+}
+
+func (g *SVGGraph) AddConcurrency(forks ...GraphDrawer) {
+	if len(forks) == 0 {
+		return
+	}
+
+	f := &svgFork{}
+	g.current.nodes = append(g.current.nodes, f)
+	for _, fork := range forks {
+		s := &svgSequence{}
+		f.branches = append(f.branches, s)
+		g.within(s, fork)
+	}
+}
+
+func (g *SVGGraph) AddActivity(label string) {
+	g.current.nodes = append(g.current.nodes, &svgActivity{label: label})
+}
+
+// String returns the SVG image of the graph
+func (g *SVGGraph) String() string {
+	w, h := g.root.size()
+	w = max(w, 2*svgTerminalR)
+
+	totalW := w + 2*svgMargin
+	totalH := svgMargin + 2*svgTerminalR + svgArrowGap + h + svgArrowGap + 2*svgTerminalR + svgMargin
+	if h == 0 {
+		totalH -= svgArrowGap
+	}
+	cx := totalW / 2
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\" "+
+		"font-family=\"sans-serif\" font-size=\"%g\">\n", totalW, totalH, totalW, totalH, svgFontSize))
+	sb.WriteString("<defs><marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"6\" markerHeight=\"6\" " +
+		"orient=\"auto-start-reverse\"><path d=\"M 0 0 L 10 5 L 0 10 z\" fill=\"#333\"/></marker></defs>\n")
+	sb.WriteString(fmt.Sprintf("<rect width=\"%g\" height=\"%g\" fill=\"white\"/>\n", totalW, totalH))
+
+	// start
+	y := svgMargin
+	sb.WriteString(fmt.Sprintf("<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"#222\"/>\n", cx, y+svgTerminalR, svgTerminalR))
+	y += 2 * svgTerminalR
+
+	if h > 0 {
+		svgArrow(&sb, cx, y, cx, y+svgArrowGap)
+		y += svgArrowGap
+		g.root.draw(&sb, cx-w/2, y)
+		y += h
+	}
+
+	// end
+	svgArrow(&sb, cx, y, cx, y+svgArrowGap)
+	y += svgArrowGap
+	sb.WriteString(fmt.Sprintf("<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"white\" stroke=\"#222\" stroke-width=\"2\"/>\n", cx, y+svgTerminalR, svgTerminalR))
+	sb.WriteString(fmt.Sprintf("<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"#222\"/>\n", cx, y+svgTerminalR, svgTerminalR-4))
+
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// WriteTo writes the SVG image of the graph into the writer
+func (g *SVGGraph) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, g.String())
+	return int64(n), err
+}
+
+// within draws into the given sequence, restoring the current one afterwards
+func (g *SVGGraph) within(s *svgSequence, draw GraphDrawer) {
+	prev := g.current
+	g.current = s
+	draw(g)
+	g.current = prev
+}
+
+func (s *svgSequence) size() (float64, float64) {
+	var w, h float64
+	for i, n := range s.nodes {
+		nw, nh := n.size()
+		w = max(w, nw)
+		h += nh
+		if i > 0 {
+			h += svgArrowGap
+		}
+	}
+	return w, h
+}
+
+func (s *svgSequence) draw(sb *strings.Builder, x, y float64) {
+	w, _ := s.size()
+	cx := x + w/2
+	for i, n := range s.nodes {
+		if i > 0 {
+			svgArrow(sb, cx, y, cx, y+svgArrowGap)
+			y += svgArrowGap
+		}
+		nw, nh := n.size()
+		n.draw(sb, cx-nw/2, y)
+		y += nh
+	}
+}
+
+func (a *svgActivity) size() (float64, float64) {
+	return max(svgMinBoxWidth, svgTextWidth(a.label)+2*svgTextPadding), svgBoxHeight
+}
+
+func (a *svgActivity) draw(sb *strings.Builder, x, y float64) {
+	w, h := a.size()
+	sb.WriteString(fmt.Sprintf("<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" rx=\"8\" fill=\"#fefece\" stroke=\"#a80036\"/>\n", x, y, w, h))
+	svgText(sb, x+w/2, y+h/2, a.label)
+}
+
+func (d *svgDecision) size() (float64, float64) {
+	dw := svgTextWidth(d.statement) + svgDiamondExtra
+	yw, yh := d.yes.size()
+	nw, nh := d.no.size()
+	bw := max(yw, svgMinColumn) + svgBranchGap + max(nw, svgMinColumn)
+	return max(dw, bw), svgDiamondH + svgArrowGap + max(yh, nh) + svgArrowGap + svgMergeSize
+}
+
+func (d *svgDecision) draw(sb *strings.Builder, x, y float64) {
+	w, h := d.size()
+	cx := x + w/2
+	dw := svgTextWidth(d.statement) + svgDiamondExtra
+
+	svgDiamond(sb, cx, y, dw, svgDiamondH)
+	svgText(sb, cx, y+svgDiamondH/2, d.statement)
+
+	yw, _ := d.yes.size()
+	nw, _ := d.no.size()
+	yc, nc := max(yw, svgMinColumn), max(nw, svgMinColumn)
+	left := cx - (yc+svgBranchGap+nc)/2
+
+	top := y + svgDiamondH + svgArrowGap
+	mergeY := y + h - svgMergeSize
+	for i, b := range []*svgSequence{d.yes, d.no} {
+		bcx := left + yc/2
+		label := "yes"
+		if i == 1 {
+			bcx = left + yc + svgBranchGap + nc/2
+			label = "no"
+		}
+		svgBranch(sb, b, cx, y+svgDiamondH, bcx, top, mergeY)
+		sb.WriteString(fmt.Sprintf("<text x=\"%g\" y=\"%g\" font-size=\"%g\">%s</text>\n", bcx+4, top-4, svgFontSize-2, label))
+	}
+
+	svgDiamond(sb, cx, mergeY, svgMergeSize, svgMergeSize)
+}
+
+func (f *svgFork) size() (float64, float64) {
+	var w, h float64
+	for i, b := range f.branches {
+		bw, bh := b.size()
+		w += max(bw, svgMinColumn)
+		if i > 0 {
+			w += svgBranchGap
+		}
+		h = max(h, bh)
+	}
+	return w, svgBarHeight + svgArrowGap + h + svgArrowGap + svgBarHeight
+}
+
+func (f *svgFork) draw(sb *strings.Builder, x, y float64) {
+	w, h := f.size()
+	sb.WriteString(fmt.Sprintf("<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"#222\"/>\n", x, y, w, svgBarHeight))
+
+	top := y + svgBarHeight + svgArrowGap
+	joinY := y + h - svgBarHeight
+	left := x
+	for _, b := range f.branches {
+		bw, _ := b.size()
+		col := max(bw, svgMinColumn)
+		bcx := left + col/2
+		svgBranch(sb, b, bcx, y+svgBarHeight, bcx, top, joinY)
+		left += col + svgBranchGap
+	}
+
+	sb.WriteString(fmt.Sprintf("<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"#222\"/>\n", x, joinY, w, svgBarHeight))
+}
+
+// svgBranch draws a branch centered at bcx starting at top, linking it from (fromX, fromY) and into the
+// closing node (centered at the parent's center) at closeY
+func svgBranch(sb *strings.Builder, b *svgSequence, fromX, fromY, bcx, top, closeY float64) {
+	bw, bh := b.size()
+	midTop := top - svgArrowGap/2
+	midBottom := closeY - svgArrowGap/2
+
+	if bh == 0 { // empty branch, straight through
+		sb.WriteString(fmt.Sprintf("<polyline points=\"%g,%g %g,%g %g,%g %g,%g %g,%g\" fill=\"none\" stroke=\"#333\" marker-end=\"url(#arrow)\"/>\n",
+			fromX, fromY, fromX, midTop, bcx, midTop, bcx, midBottom, fromX, closeY))
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("<polyline points=\"%g,%g %g,%g %g,%g %g,%g\" fill=\"none\" stroke=\"#333\" marker-end=\"url(#arrow)\"/>\n",
+		fromX, fromY, fromX, midTop, bcx, midTop, bcx, top))
+	b.draw(sb, bcx-bw/2, top)
+	sb.WriteString(fmt.Sprintf("<polyline points=\"%g,%g %g,%g %g,%g %g,%g\" fill=\"none\" stroke=\"#333\" marker-end=\"url(#arrow)\"/>\n",
+		bcx, top+bh, bcx, midBottom, fromX, midBottom, fromX, closeY))
+}
+
+func svgArrow(sb *strings.Builder, x1, y1, x2, y2 float64) {
+	sb.WriteString(fmt.Sprintf("<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"#333\" marker-end=\"url(#arrow)\"/>\n", x1, y1, x2, y2))
+}
+
+func svgDiamond(sb *strings.Builder, cx, y, w, h float64) {
+	sb.WriteString(fmt.Sprintf("<polygon points=\"%g,%g %g,%g %g,%g %g,%g\" fill=\"#fefece\" stroke=\"#a80036\"/>\n",
+		cx, y, cx+w/2, y+h/2, cx, y+h, cx-w/2, y+h/2))
+}
+
+func svgText(sb *strings.Builder, cx, cy float64, text string) {
+	sb.WriteString(fmt.Sprintf("<text x=\"%g\" y=\"%g\" text-anchor=\"middle\" dominant-baseline=\"central\">%s</text>\n", cx, cy, escapeXML(text)))
+}
+
+func svgTextWidth(text string) float64 {
+	return float64(len([]rune(text))) * svgCharWidth
+}
+
+// escapeXML escapes a text so it can be placed as content or attribute of an XML element
+func escapeXML(text string) string {
+	return strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		"\"", "&quot;",
+		"'", "&apos;",
+	).Replace(text)
+}
@@ -0,0 +1,92 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func svgTexts(t *testing.T, content string) []string {
+	var texts []string
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	inText := false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if !assert.Nil(t, err) {
+			break
+		}
+		switch v := tok.(type) {
+		case xml.StartElement:
+			inText = v.Name.Local == "text"
+		case xml.EndElement:
+			inText = false
+		case xml.CharData:
+			if inText {
+				texts = append(texts, string(v))
+			}
+		}
+	}
+	return texts
+}
+
+func TestSVGGraph_GivenAComplexGraph_WhenRendered_ThenAValidSVGWithEveryLabelIsWritten(t *testing.T) {
+	diagram := pipeline.NewSVGGraph()
+	drawComplexGraph(diagram)
+
+	content := diagram.String()
+
+	assert.True(t, strings.HasPrefix(content, "<svg xmlns=\"http://www.w3.org/2000/svg\""))
+	assert.Equal(t, []string{
+		"beginning",
+		"branch 1", "branch 1 again",
+		"is this a test?", "yes, this is a test", "yes", "no",
+		"nested?", "inner", "yes", "inner no", "no", "yes", "outer no", "no",
+		"end",
+	}, svgTexts(t, content))
+}
+
+func TestSVGGraph_GivenAnEmptyGraph_WhenRendered_ThenStartIsLinkedToEnd(t *testing.T) {
+	content := pipeline.NewSVGGraph().String()
+
+	assert.Empty(t, svgTexts(t, content))
+	assert.Equal(t, 3, strings.Count(content, "<circle"))
+	assert.Equal(t, 1, strings.Count(content, "<line"))
+}
+
+func TestSVGGraph_GivenLabelsWithSpecialCharacters_WhenRendered_ThenTheyAreEscaped(t *testing.T) {
+	diagram := pipeline.NewSVGGraph()
+	diagram.AddActivity("<a & \"b\">")
+
+	content := diagram.String()
+
+	assert.Contains(t, content, "&lt;a &amp; &quot;b&quot;&gt;")
+	assert.Equal(t, []string{"<a & \"b\">"}, svgTexts(t, content))
+}
+
+func TestSVGGraph_GivenAGraph_WhenAddingZeroConcurrentCases_ThenNothingHappens(t *testing.T) {
+	diagram := pipeline.NewSVGGraph()
+	diagram.AddConcurrency()
+
+	assert.Equal(t, pipeline.NewSVGGraph().String(), diagram.String())
+}
+
+func TestSVGGraph_GivenAGraph_WhenWrittenTo_ThenSameAsStringIsWritten(t *testing.T) {
+	diagram := pipeline.NewSVGGraph()
+	diagram.AddActivity("a")
+	var buf bytes.Buffer
+
+	n, err := diagram.WriteTo(&buf)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, diagram.String(), buf.String())
+}
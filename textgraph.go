@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type (
+	// TextOptions available when drawing a text graph
+	TextOptions struct {
+		// Width is the maximum width (in characters) of each line, labels exceeding it are truncated.
+		// By default lines aren't truncated
+		Width int
+		// ASCII draws the tree with ASCII characters only, instead of Unicode box-drawing ones
+		ASCII bool
+	}
+
+	// TextGraph represents a graph that renders itself as an indented tree, suitable for terminals.
+	//
+	//	pipeline
+	//	├── get_driver
+	//	├── fork
+	//	│   ├── branch 1
+	//	│   │   └── get_location
+	//	│   └── branch 2
+	//	│       └── get_tracking
+	//	└── if is_close
+	//	    ├── then
+	//	    │   └── notify_driver_close
+	//	    └── else
+	TextGraph struct {
+		Options TextOptions
+
+		root *textNode
+		// current is the node whose children are being drawn
+		current *textNode
+	}
+
+	textNode struct {
+		label    string
+		children []*textNode
+	}
+
+	textGlyphs struct {
+		branch, last, pipe, space, ellipsis string
+	}
+)
+
+var (
+	unicodeTextGlyphs = textGlyphs{branch: "├── ", last: "└── ", pipe: "│   ", space: "    ", ellipsis: "…"}
+	asciiTextGlyphs   = textGlyphs{branch: "|-- ", last: "`-- ", pipe: "|   ", space: "    ", ellipsis: "..."}
+)
+
+// NewTextGraph creates a tree diagram that represents one, as specified
+func NewTextGraph(options TextOptions) *TextGraph {
+	root := &textNode{label: "pipeline"}
+	return &TextGraph{
+		Options: options,
+		root:    root,
+		current: root,
+	}
+}
+
+func (g *TextGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
+	n := g.add(fmt.Sprintf("if %s", statement))
+
+	g.within(n, "then", yes)
+	g.within(n, "else", no)
+}
+
+func (g *TextGraph) AddConcurrency(forks ...GraphDrawer) {
+	if len(forks) == 0 {
+		return
+	}
+
+	n := g.add("fork")
+	for i, fork := range forks {
+		g.within(n, fmt.Sprintf("branch %d", i+1), fork)
+	}
+}
+
+func (g *TextGraph) AddActivity(label string) {
+	g.add(label)
+}
+
+// String returns the tree representation of the graph
+func (g *TextGraph) String() string {
+	glyphs := unicodeTextGlyphs
+	if g.Options.ASCII {
+		glyphs = asciiTextGlyphs
+	}
+
+	var sb strings.Builder
+	g.write(&sb, glyphs, g.root, "", "")
+	return sb.String()
+}
+
+// WriteTo writes the tree representation of the graph into the writer
+func (g *TextGraph) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, g.String())
+	return int64(n), err
+}
+
+func (g *TextGraph) add(label string) *textNode {
+	n := &textNode{label: label}
+	g.current.children = append(g.current.children, n)
+	return n
+}
+
+// within draws as children of a new labeled child of the given node, restoring the current one afterwards
+func (g *TextGraph) within(parent *textNode, label string, draw GraphDrawer) {
+	n := &textNode{label: label}
+	parent.children = append(parent.children, n)
+
+	prev := g.current
+	g.current = n
+	draw(g)
+	g.current = prev
+}
+
+func (g *TextGraph) write(sb *strings.Builder, glyphs textGlyphs, n *textNode, prefix, childPrefix string) {
+	sb.WriteString(g.truncate(prefix, n.label, glyphs.ellipsis))
+	sb.WriteString("\n")
+
+	for i, c := range n.children {
+		if i == len(n.children)-1 {
+			g.write(sb, glyphs, c, childPrefix+glyphs.last, childPrefix+glyphs.space)
+		} else {
+			g.write(sb, glyphs, c, childPrefix+glyphs.branch, childPrefix+glyphs.pipe)
+		}
+	}
+}
+
+// truncate the label so the line (prefix and label) doesn't exceed the width
+func (g *TextGraph) truncate(prefix, label, ellipsis string) string {
+	label = strings.NewReplacer("\r\n", " ", "\n", " ").Replace(label)
+	line := []rune(prefix + label)
+	if g.Options.Width <= 0 || len(line) <= g.Options.Width {
+		return string(line)
+	}
+
+	keep := g.Options.Width - len([]rune(ellipsis))
+	if keep <= len([]rune(prefix)) { // there's no room for the label, keep the tree structure anyway
+		return prefix + ellipsis
+	}
+	return string(line[:keep]) + ellipsis
+}
@@ -0,0 +1,89 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestTextGraph_GivenAComplexGraph_WhenStringRepresentationIsAsked_ThenUnicodeTreeIsRendered(t *testing.T) {
+	diagram := pipeline.NewTextGraph(pipeline.TextOptions{})
+	drawComplexGraph(diagram)
+
+	content := diagram.String()
+
+	assert.Equal(t, `pipeline
+├── beginning
+├── fork
+│   ├── branch 1
+│   │   ├── branch 1
+│   │   └── branch 1 again
+│   ├── branch 2
+│   │   └── if is this a test?
+│   │       ├── then
+│   │       │   └── yes, this is a test
+│   │       └── else
+│   └── branch 3
+├── if nested?
+│   ├── then
+│   │   └── if inner
+│   │       ├── then
+│   │       └── else
+│   │           └── inner no
+│   └── else
+│       └── outer no
+└── end
+`, content)
+}
+
+func TestTextGraph_GivenASCIIOption_WhenStringRepresentationIsAsked_ThenASCIITreeIsRendered(t *testing.T) {
+	diagram := pipeline.NewTextGraph(pipeline.TextOptions{ASCII: true})
+	diagram.AddDecision("cond", func(graph pipeline.Graph) {
+		graph.AddActivity("a")
+	}, func(graph pipeline.Graph) {})
+	diagram.AddActivity("b")
+
+	content := diagram.String()
+
+	assert.Equal(t, "pipeline\n|-- if cond\n|   |-- then\n|   |   `-- a\n|   `-- else\n`-- b\n", content)
+}
+
+func TestTextGraph_GivenAWidth_WhenStringRepresentationIsAsked_ThenLongLinesAreTruncated(t *testing.T) {
+	diagram := pipeline.NewTextGraph(pipeline.TextOptions{Width: 12})
+	diagram.AddActivity("short")
+	diagram.AddActivity("a very long label\nwith lines")
+
+	content := diagram.String()
+
+	assert.Equal(t, "pipeline\n├── short\n└── a very …\n", content)
+}
+
+func TestTextGraph_GivenATinyWidth_WhenStringRepresentationIsAsked_ThenStructureIsKept(t *testing.T) {
+	diagram := pipeline.NewTextGraph(pipeline.TextOptions{Width: 4, ASCII: true})
+	diagram.AddActivity("label")
+
+	content := diagram.String()
+
+	assert.Equal(t, "p...\n`-- ...\n", content)
+}
+
+func TestTextGraph_GivenAGraph_WhenAddingZeroConcurrentCases_ThenNothingHappens(t *testing.T) {
+	diagram := pipeline.NewTextGraph(pipeline.TextOptions{})
+	diagram.AddConcurrency()
+
+	assert.Equal(t, "pipeline\n", diagram.String())
+}
+
+func TestTextGraph_GivenAGraph_WhenWrittenTo_ThenSameAsStringIsWritten(t *testing.T) {
+	diagram := pipeline.NewTextGraph(pipeline.TextOptions{})
+	diagram.AddActivity("a")
+	var buf bytes.Buffer
+
+	_, err := diagram.WriteTo(&buf)
+
+	assert.Nil(t, err)
+	assert.Equal(t, diagram.String(), buf.String())
+}
@@ -2,19 +2,20 @@ package pipeline
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"os"
-	"time"
 )
 
 type (
-	// TracedStep decorates a step with tracing capabilities, logging the duration of the
-	// step execution, time of execution and its result.
+	// TracedStep decorates a step with tracing capabilities, creating a span for the
+	// step execution that records its duration, time of execution and its result.
+	//
+	// Spans are propagated through the context, hence traced steps nested inside other
+	// traced steps will produce child spans.
 	TracedStep[I, O any] struct {
 		name   string
 		step   Step[I, O]
-		writer io.Writer
+		tracer Tracer
 	}
 )
 
@@ -25,29 +26,56 @@ func NewTracedStep[I, O any](name string, step Step[I, O]) TracedStep[I, O] {
 
 // NewTracedStepWithWriter creates traced step that will log the execution time of the step to the writer
 func NewTracedStepWithWriter[I, O any](name string, step Step[I, O], writer io.Writer) TracedStep[I, O] {
+	return NewTracedStepWithTracer(name, step, NewWriterTracer(writer))
+}
+
+// NewTracedStepWithTracer creates traced step that will report the execution of the step as a span of the tracer
+func NewTracedStepWithTracer[I, O any](name string, step Step[I, O], tracer Tracer) TracedStep[I, O] {
 	return TracedStep[I, O]{
 		name:   name,
 		step:   step,
-		writer: writer,
+		tracer: tracer,
 	}
 }
 
+// Draw the inner step, stereotyping its activities as traced
 func (t TracedStep[I, O]) Draw(graph Graph) {
-	t.step.Draw(graph)
+	DrawStereotyped(graph, "traced", t.step.Draw)
 }
 
-func (t TracedStep[I, O]) Run(ctx context.Context, in I) (O, error) {
-	start := time.Now()
+// Name of the span this step reports
+func (t TracedStep[I, O]) Name() string {
+	return t.name
+}
+
+// Kind of this step, see StepKindTraced
+func (t TracedStep[I, O]) Kind() StepKind {
+	return StepKindTraced
+}
 
-	res, err := t.step.Run(ctx, in)
+// Children of this step, the traced one
+func (t TracedStep[I, O]) Children() []DrawableGraph {
+	return []DrawableGraph{t.step}
+}
 
-	var message string
-	if err == nil {
-		message = "Success"
-	} else {
-		message = fmt.Sprintf("Failure: %s", err.Error())
+func (t TracedStep[I, O]) problems() []string {
+	var res []string
+	if t.step == nil {
+		res = append(res, "nil traced step")
+	}
+	if t.tracer == nil {
+		res = append(res, "nil tracer")
 	}
+	return res
+}
 
-	fmt.Fprintf(t.writer, "[STAGE] %s | %s | %s | %s\n", start.Format("2006-01-02 - 15:04:05"), t.name, time.Since(start), message)
-	return res, err
+func (t TracedStep[I, O]) Run(ctx context.Context, in I) (O, error) {
+	ctx, span := t.tracer.Start(ctx, t.name)
+	defer span.End()
+
+	res, err := t.step.Run(withStepPath(ctx, t, 0), in)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return res, prependStepPath(err, t, 0)
 }
@@ -63,3 +63,33 @@ func TestTrace_GivenAStepToDraw_WhenDrawn_ThenDelegatesToInnerStep(t *testing.T)
 	mockGraph.AssertExpectations(t)
 	mockStep.AssertExpectations(t)
 }
+
+func TestTrace_GivenATracer_WhenRun_ThenASpanIsRecordedWithTheError(t *testing.T) {
+	expectedErr := errors.New("some error")
+	mockStep := new(mockStep[any, any])
+	mockStep.On("Run", mock.Anything, 1).Return(nil, expectedErr)
+	tracer := pipeline.NewRecordingTracer()
+	step := pipeline.NewTracedStepWithTracer[any, any]("test name", mockStep, tracer)
+
+	_, _ = step.Run(context.Background(), 1)
+
+	spans := tracer.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "test name", spans[0].Name)
+	assert.Equal(t, expectedErr, spans[0].Err)
+}
+
+func TestTrace_GivenATracer_WhenRun_ThenInnerStepRunsWithTheSpanInContext(t *testing.T) {
+	tracer := pipeline.NewRecordingTracer()
+	inner := pipeline.NewUnitStep("inner", func(ctx context.Context, in int) (int, error) {
+		pipeline.SpanFromContext(ctx).SetAttributes(pipeline.NewAttribute("key", "value"))
+		return in, nil
+	})
+	step := pipeline.NewTracedStepWithTracer[int, int]("test name", inner, tracer)
+
+	_, _ = step.Run(context.Background(), 1)
+
+	spans := tracer.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, []pipeline.Attribute{pipeline.NewAttribute("key", "value")}, spans[0].Attributes)
+}
@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+type (
+	// Tracer creates spans representing the execution of steps.
+	//
+	// Spans are propagated through the context, hence a span started while another one
+	// is present in the context should be considered a child of it.
+	Tracer interface {
+		// Start a span with the given name, as a child of the span present in the context (if any).
+		// The returned context carries the new span and should be used for running the traced work.
+		Start(ctx context.Context, name string) (context.Context, Span)
+	}
+
+	// Span represents a single traced execution.
+	Span interface {
+		// SetAttributes adds attributes describing the execution.
+		SetAttributes(attrs ...Attribute)
+		// RecordError records the error the execution ended with.
+		// Note that halt signals (see IsHalt) are recorded too, implementations should decide how to treat them.
+		RecordError(err error)
+		// End the span. A span shouldn't be modified after ending it.
+		End()
+	}
+
+	// Attribute is a key-value pair describing a span.
+	Attribute struct {
+		Key   string
+		Value any
+	}
+
+	// WriterTracer is a tracer that writes a single line per span into a writer once it ends.
+	WriterTracer struct {
+		writer io.Writer
+	}
+
+	writerSpan struct {
+		writer io.Writer
+		name   string
+		start  time.Time
+		err    error
+	}
+
+	noopSpan struct{}
+
+	spanContextKey struct{}
+)
+
+// NewAttribute creates an attribute of the given key and value
+func NewAttribute(key string, value any) Attribute {
+	return Attribute{
+		Key:   key,
+		Value: value,
+	}
+}
+
+// ContextWithSpan returns a copy of the context carrying the given span.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the current span of the context. If there's none, a span that does
+// nothing is returned, so it's always safe to use.
+func SpanFromContext(ctx context.Context) Span {
+	if s, ok := ctx.Value(spanContextKey{}).(Span); ok {
+		return s
+	}
+	return noopSpan{}
+}
+
+// NewWriterTracer creates a tracer that will log the start time, duration and result of each span to the writer
+func NewWriterTracer(writer io.Writer) *WriterTracer {
+	return &WriterTracer{
+		writer: writer,
+	}
+}
+
+func (t *WriterTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &writerSpan{
+		writer: t.writer,
+		name:   name,
+		start:  time.Now(),
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+func (s *writerSpan) SetAttributes(...Attribute) {
+	// attributes aren't part of the line format
+}
+
+func (s *writerSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *writerSpan) End() {
+	var message string
+	if s.err == nil {
+		message = "Success"
+	} else if IsHalt(s.err) {
+		message = "Halted"
+	} else {
+		message = fmt.Sprintf("Failure: %s", s.err.Error())
+	}
+
+	fmt.Fprintf(s.writer, "[STAGE] %s | %s | %s | %s\n", s.start.Format("2006-01-02 - 15:04:05"), s.name, time.Since(s.start), message)
+}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
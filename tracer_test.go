@@ -0,0 +1,56 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestSpanFromContext_GivenAContextWithoutSpan_ThenANoopSpanIsReturned(t *testing.T) {
+	span := pipeline.SpanFromContext(context.Background())
+
+	assert.NotNil(t, span)
+	assert.NotPanics(t, func() {
+		span.SetAttributes(pipeline.NewAttribute("key", "value"))
+		span.RecordError(errors.New("some error"))
+		span.End()
+	})
+}
+
+func TestSpanFromContext_GivenAContextWithSpan_ThenItIsReturned(t *testing.T) {
+	_, span := pipeline.NewRecordingTracer().Start(context.Background(), "name")
+	ctx := pipeline.ContextWithSpan(context.Background(), span)
+
+	assert.Equal(t, span, pipeline.SpanFromContext(ctx))
+}
+
+func TestWriterTracer_GivenASpan_WhenEnded_ThenStageLineIsWritten(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	tracer := pipeline.NewWriterTracer(writer)
+	validator := regexp.MustCompile(`^\[STAGE] \d{4}-\d{2}-\d{2} - \d{2}:\d{2}:\d{2} \| test name \| [.\d]+[µnm]s \| Success\n$`)
+
+	ctx, span := tracer.Start(context.Background(), "test name")
+	span.SetAttributes(pipeline.NewAttribute("key", "value"))
+	span.End()
+
+	assert.Equal(t, span, pipeline.SpanFromContext(ctx))
+	assert.True(t, validator.Match(writer.Bytes()))
+}
+
+func TestWriterTracer_GivenASpanWithError_WhenEnded_ThenFailureLineIsWritten(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	tracer := pipeline.NewWriterTracer(writer)
+	validator := regexp.MustCompile(`^\[STAGE] .* \| test name \| [.\d]+[µnm]s \| Failure: some error\n$`)
+
+	_, span := tracer.Start(context.Background(), "test name")
+	span.RecordError(errors.New("some error"))
+	span.End()
+
+	assert.True(t, validator.Match(writer.Bytes()))
+}
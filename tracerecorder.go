@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// RecordingTracer is a tracer that keeps every ended span in memory.
+	// It's meant to be used in tests to assert how steps were traced.
+	RecordingTracer struct {
+		mux   sync.Mutex
+		spans []RecordedSpan
+	}
+
+	// RecordedSpan is the snapshot of a span that has already ended.
+	RecordedSpan struct {
+		// ID of the span
+		ID string
+		// ParentID is the ID of the span this one is a child of. It's empty for root spans
+		ParentID string
+		// Name the span was started with
+		Name string
+		// Start and End time of the span
+		Start, End time.Time
+		// Attributes set on the span, in the order they were set
+		Attributes []Attribute
+		// Err the span was ended with, if any
+		Err error
+	}
+
+	recordingSpan struct {
+		tracer *RecordingTracer
+		mux    sync.Mutex
+		data   RecordedSpan
+	}
+)
+
+// NewRecordingTracer creates a tracer that records spans in memory
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &recordingSpan{
+		tracer: t,
+		data: RecordedSpan{
+			ID:    uuid.New().String(),
+			Name:  name,
+			Start: time.Now(),
+		},
+	}
+	if p, ok := SpanFromContext(ctx).(*recordingSpan); ok && p.tracer == t {
+		s.data.ParentID = p.data.ID
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+// Spans returns the ended spans, in the order they ended
+func (t *RecordingTracer) Spans() []RecordedSpan {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return append([]RecordedSpan(nil), t.spans...)
+}
+
+// Children returns the ended spans that are direct children of the given span ID, in the order they ended.
+// An empty ID returns the root spans.
+func (t *RecordingTracer) Children(id string) []RecordedSpan {
+	var res []RecordedSpan
+	for _, s := range t.Spans() {
+		if s.ParentID == id {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Reset discards all recorded spans
+func (t *RecordingTracer) Reset() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.spans = nil
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.Err = err
+}
+
+func (s *recordingSpan) End() {
+	s.mux.Lock()
+	s.data.End = time.Now()
+	data := s.data
+	s.mux.Unlock()
+
+	s.tracer.mux.Lock()
+	defer s.tracer.mux.Unlock()
+	s.tracer.spans = append(s.tracer.spans, data)
+}
@@ -0,0 +1,62 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func TestRecordingTracer_GivenNestedTracedSteps_WhenRun_ThenASpanTreeIsRecorded(t *testing.T) {
+	tracer := pipeline.NewRecordingTracer()
+	unit := func(name string) pipeline.Step[int, int] {
+		return pipeline.NewTracedStepWithTracer[int, int](name, pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+			return i + 1, nil
+		}), tracer)
+	}
+	step := pipeline.NewTracedStepWithTracer[int, int](
+		"root",
+		pipeline.NewSequentialStep[int, int, int](
+			unit("first"),
+			pipeline.NewConcurrentStep([]pipeline.Step[int, int]{unit("second"), unit("third")}, func(ctx context.Context, a, b int) (int, error) {
+				return a + b, nil
+			}),
+		),
+		tracer,
+	)
+
+	_, err := step.Run(context.Background(), 1)
+
+	assert.Nil(t, err)
+	roots := tracer.Children("")
+	assert.Len(t, roots, 1)
+	assert.Equal(t, "root", roots[0].Name)
+	children := tracer.Children(roots[0].ID)
+	assert.Len(t, children, 3)
+	for _, c := range children {
+		assert.Empty(t, tracer.Children(c.ID))
+		assert.False(t, c.End.Before(c.Start))
+	}
+}
+
+func TestRecordingTracer_GivenSpansFromAnotherTracer_WhenStarting_ThenTheyAreNotConsideredParents(t *testing.T) {
+	tracer := pipeline.NewRecordingTracer()
+	ctx, _ := pipeline.NewRecordingTracer().Start(context.Background(), "foreign")
+
+	_, span := tracer.Start(ctx, "own")
+	span.End()
+
+	assert.Empty(t, tracer.Spans()[0].ParentID)
+}
+
+func TestRecordingTracer_GivenRecordedSpans_WhenReset_ThenTheyAreDiscarded(t *testing.T) {
+	tracer := pipeline.NewRecordingTracer()
+	_, span := tracer.Start(context.Background(), "name")
+	span.End()
+
+	tracer.Reset()
+
+	assert.Empty(t, tracer.Spans())
+}
@@ -2,48 +2,111 @@ package pipeline
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// UMLGraph represents a graph that can render itself into UML
-type UMLGraph struct {
-	sb strings.Builder
-}
+type (
+	// UMLGraphOptions available when drawing an UML graph
+	UMLGraphOptions struct {
+		// Theme of the diagram (eg. "cerulean"), by default no theme is used
+		Theme string
+		// SkinParams of the diagram (eg. "ActivityBackgroundColor": "#ffffff"), written sorted by name
+		SkinParams map[string]string
+		// Styles of the activities, by label. They are merged on top of the styles the steps draw with
+		Styles map[string]ActivityStyle
+		// Swimlanes groups the activities into swimlanes by the owner of the steps drawing them (see StepMetadata).
+		// Activities without an owner stay in the swimlane of the previous one
+		Swimlanes bool
+	}
+
+	// UMLGraph represents a graph that can render itself into UML
+	UMLGraph struct {
+		Options UMLGraphOptions
+
+		sb strings.Builder
+		// lane is the current swimlane
+		lane string
+		// stereotypes of the activities being drawn (see AddStereotyped), outermost first
+		stereotypes []string
+	}
+)
+
+// umlEscaper replaces the characters that would break the PlantUML syntax of a label (ending an activity,
+// closing a condition or splitting a line) with their unicode entities
+var umlEscaper = strings.NewReplacer(
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+	";", "<U+003B>",
+	"|", "<U+007C>",
+	"(", "<U+0028>",
+	")", "<U+0029>",
+	`"`, "<U+0022>",
+)
 
 // NewUMLGraph createsn UML Activity graph diagram that represents one
 func NewUMLGraph() *UMLGraph {
 	return &UMLGraph{}
 }
 
+// NewUMLGraphWithOptions creates an UML Activity graph diagram that represents one, as specified
+func NewUMLGraphWithOptions(options UMLGraphOptions) *UMLGraph {
+	return &UMLGraph{
+		Options: options,
+	}
+}
+
 func (p *UMLGraph) AddDecision(statement string, yes GraphDrawer, no GraphDrawer) {
-	p.sb.WriteString(fmt.Sprintf("if (%s) then (yes)\n", statement))
+	p.decision(statement, "yes", "no", func() { yes(p) }, func() { no(p) })
+}
 
-	yes(p)
+func (p *UMLGraph) AddConcurrency(forks ...GraphDrawer) {
+	p.concurrency(len(forks), func(i int) { forks[i](p) })
+}
 
-	p.sb.WriteString("else (no)\n")
+func (p *UMLGraph) AddActivity(label string) {
+	p.AddStyledActivity(label, ActivityStyle{})
+}
 
-	no(p)
+func (p *UMLGraph) AddStyledActivity(label string, style ActivityStyle) {
+	p.activity(escapeUML(label), p.style(label, p.stereotyped(style)))
+}
 
-	p.sb.WriteString("endif\n")
+// AddDescribedActivity creates an action entry followed by a note with the metadata. If swimlanes are enabled,
+// the action is placed in the swimlane of its owner
+func (p *UMLGraph) AddDescribedActivity(label string, style ActivityStyle, metadata StepMetadata) {
+	p.swimlane(metadata.Owner)
+	p.AddStyledActivity(label, style)
+	p.describe(metadata)
 }
 
-func (p *UMLGraph) AddConcurrency(forks ...GraphDrawer) {
-	if len(forks) == 0 {
-		return
-	}
+func (p *UMLGraph) AddLoop(condition string, body GraphDrawer) {
+	p.loop(condition, func() { body(p) })
+}
 
-	p.sb.WriteString("fork\n")
-	for i, fork := range forks {
-		fork(p)
-		if len(forks) != (i + 1) {
-			p.sb.WriteString("fork again\n")
-		}
+func (p *UMLGraph) AddSwitch(statement string, cases ...GraphCase) {
+	labels := make([]string, len(cases))
+	for i, c := range cases {
+		labels[i] = c.Label
 	}
-	p.sb.WriteString("end fork\n")
+	p.switchCases(statement, labels, func(i int) { cases[i].Draw(p) })
 }
 
-func (p *UMLGraph) AddActivity(label string) {
-	p.sb.WriteString(fmt.Sprintf(":%s;\n", label))
+func (p *UMLGraph) AddNote(text string) {
+	p.note(text)
+}
+
+func (p *UMLGraph) AddErrorHandler(label string, body GraphDrawer, handler GraphDrawer) {
+	p.errorHandler(label, func() { body(p) }, func() { handler(p) })
+}
+
+func (p *UMLGraph) AddPartition(name string, body GraphDrawer) {
+	p.partition(name, func() { body(p) })
+}
+
+func (p *UMLGraph) AddStereotyped(stereotype string, body GraphDrawer) {
+	p.stereotype(stereotype, func() { body(p) })
 }
 
 func (p *UMLGraph) String() string {
@@ -51,6 +114,7 @@ func (p *UMLGraph) String() string {
 
 	// New
 	sb.WriteString("@startuml\n")
+	p.header(&sb)
 	sb.WriteString("start\n")
 
 	sb.WriteString(p.sb.String())
@@ -61,3 +125,190 @@ func (p *UMLGraph) String() string {
 
 	return sb.String()
 }
+
+// header writes the theme and skin params of the diagram
+func (p *UMLGraph) header(sb *strings.Builder) {
+	if len(p.Options.Theme) > 0 {
+		sb.WriteString(fmt.Sprintf("!theme %s\n", p.Options.Theme))
+	}
+
+	names := make([]string, 0, len(p.Options.SkinParams))
+	for n := range p.Options.SkinParams {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		sb.WriteString(fmt.Sprintf("skinparam %s %s\n", n, p.Options.SkinParams[n]))
+	}
+}
+
+// style merges the style configured for the label on top of the given one
+func (p *UMLGraph) style(label string, style ActivityStyle) ActivityStyle {
+	configured, ok := p.Options.Styles[label]
+	if !ok {
+		return style
+	}
+
+	if len(configured.Color) > 0 {
+		style.Color = configured.Color
+	}
+	stereotypes := make([]string, 0, len(style.Stereotypes)+len(configured.Stereotypes))
+	stereotypes = append(stereotypes, style.Stereotypes...)
+	style.Stereotypes = append(stereotypes, configured.Stereotypes...)
+	return style
+}
+
+// stereotype every activity the body draws
+func (p *UMLGraph) stereotype(stereotype string, body func()) {
+	p.stereotypes = append(p.stereotypes, stereotype)
+	body()
+	p.stereotypes = p.stereotypes[:len(p.stereotypes)-1]
+}
+
+// stereotyped copy of the style, adding the stereotypes of the activities being drawn (innermost first)
+func (p *UMLGraph) stereotyped(style ActivityStyle) ActivityStyle {
+	if len(p.stereotypes) == 0 {
+		return style
+	}
+
+	stereotypes := make([]string, 0, len(style.Stereotypes)+len(p.stereotypes))
+	stereotypes = append(stereotypes, style.Stereotypes...)
+	for i := len(p.stereotypes) - 1; i >= 0; i-- {
+		stereotypes = append(stereotypes, p.stereotypes[i])
+	}
+	style.Stereotypes = stereotypes
+	return style
+}
+
+// decision writes an if/else block, labeling each branch and drawing them through the given functions
+func (p *UMLGraph) decision(statement, yesLabel, noLabel string, yes, no func()) {
+	p.sb.WriteString(fmt.Sprintf("if (%s) then (%s)\n", escapeUML(statement), yesLabel))
+
+	yes()
+
+	p.sb.WriteString(fmt.Sprintf("else (%s)\n", noLabel))
+
+	no()
+
+	p.sb.WriteString("endif\n")
+}
+
+// concurrency writes a fork block of n branches, drawing each of them through the given function
+func (p *UMLGraph) concurrency(n int, fork func(i int)) {
+	if n == 0 {
+		return
+	}
+
+	p.sb.WriteString("fork\n")
+	for i := 0; i < n; i++ {
+		fork(i)
+		if n != (i + 1) {
+			p.sb.WriteString("fork again\n")
+		}
+	}
+	p.sb.WriteString("end fork\n")
+}
+
+// activity writes an action with the given style. The text must be already escaped, as it may contain markup
+func (p *UMLGraph) activity(text string, style ActivityStyle) {
+	color := style.Color
+	if len(color) > 0 && !strings.HasPrefix(color, "#") {
+		color = "#" + color
+	}
+
+	var stereotype string
+	if len(style.Stereotypes) > 0 {
+		stereotypes := make([]string, len(style.Stereotypes))
+		for i, s := range style.Stereotypes {
+			stereotypes[i] = strings.NewReplacer("<", "", ">", "").Replace(escapeUML(s))
+		}
+		stereotype = fmt.Sprintf(" <<%s>>", strings.Join(stereotypes, ", "))
+	}
+
+	p.sb.WriteString(fmt.Sprintf("%s:%s;%s\n", color, text, stereotype))
+}
+
+// swimlane switches to the swimlane of the owner, if swimlanes are enabled
+func (p *UMLGraph) swimlane(owner string) {
+	if !p.Options.Swimlanes || len(owner) == 0 || owner == p.lane {
+		return
+	}
+	p.lane = owner
+	p.sb.WriteString(fmt.Sprintf("|%s|\n", escapeUML(owner)))
+}
+
+// describe writes a note with the metadata, attached to the last activity
+func (p *UMLGraph) describe(metadata StepMetadata) {
+	if metadata.IsZero() {
+		return
+	}
+
+	var lines []string
+	if len(metadata.Description) > 0 {
+		lines = append(lines, escapeUML(metadata.Description))
+	}
+	if len(metadata.Owner) > 0 {
+		lines = append(lines, fmt.Sprintf("owner: %s", escapeUML(metadata.Owner)))
+	}
+	if len(metadata.Tags) > 0 {
+		lines = append(lines, fmt.Sprintf("tags: %s", escapeUML(strings.Join(metadata.Tags, ", "))))
+	}
+	if len(metadata.Link) > 0 {
+		lines = append(lines, fmt.Sprintf("[[%s]]", strings.NewReplacer("]", "%5D", " ", "%20", "\n", "").Replace(metadata.Link)))
+	}
+	p.sb.WriteString(fmt.Sprintf("note right\n%s\nend note\n", strings.Join(lines, "\n")))
+}
+
+// loop writes a while block, drawing its body through the given function
+func (p *UMLGraph) loop(condition string, body func()) {
+	p.sb.WriteString(fmt.Sprintf("while (%s) is (yes)\n", escapeUML(condition)))
+
+	body()
+
+	p.sb.WriteString("endwhile (no)\n")
+}
+
+// switchCases writes a switch block with the given case labels, drawing each of them through the given function
+func (p *UMLGraph) switchCases(statement string, labels []string, draw func(i int)) {
+	if len(labels) == 0 {
+		return
+	}
+
+	p.sb.WriteString(fmt.Sprintf("switch (%s)\n", escapeUML(statement)))
+	for i, l := range labels {
+		p.sb.WriteString(fmt.Sprintf("case (%s)\n", escapeUML(l)))
+		draw(i)
+	}
+	p.sb.WriteString("endswitch\n")
+}
+
+// note writes a note attached to the last activity
+func (p *UMLGraph) note(text string) {
+	p.sb.WriteString(fmt.Sprintf("note right\n%s\nend note\n", escapeUML(text)))
+}
+
+// errorHandler writes the body followed by a red dashed error edge leading to the handler
+func (p *UMLGraph) errorHandler(label string, body, handler func()) {
+	body()
+
+	p.sb.WriteString(fmt.Sprintf("if (%s) then (error)\n", escapeUML(label)))
+	p.sb.WriteString("-[#red,dashed]->\n")
+
+	handler()
+
+	p.sb.WriteString("endif\n")
+}
+
+// partition writes a named partition, drawing its body through the given function
+func (p *UMLGraph) partition(name string, body func()) {
+	p.sb.WriteString(fmt.Sprintf("partition \"%s\" {\n", escapeUML(name)))
+
+	body()
+
+	p.sb.WriteString("}\n")
+}
+
+// escapeUML a label so it can't break the PlantUML syntax. Line breaks are kept as PlantUML ones
+func escapeUML(label string) string {
+	return umlEscaper.Replace(label)
+}
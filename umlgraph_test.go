@@ -1,6 +1,8 @@
 package pipeline_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -102,3 +104,184 @@ func TestUMLGraph_GivenAGraph_WhenStringRepresentationIsAsked_ThenCompletePlantU
 
 	assert.Equal(t, expectedContent, content)
 }
+
+func TestUMLGraph_GivenAGraph_WhenAddingALoop_ThenPlantUMLWhileIsAdded(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddLoop("has more", func(graph pipeline.Graph) {
+		graph.AddActivity("next")
+	})
+
+	content := diagram.String()
+	expectedContent := "\nwhile (has more) is (yes)\n:next;\nendwhile (no)\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenAGraph_WhenAddingASwitch_ThenPlantUMLSwitchIsAdded(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddSwitch(
+		"choice",
+		pipeline.GraphCase{Label: "A", Draw: func(graph pipeline.Graph) { graph.AddActivity("a") }},
+		pipeline.GraphCase{Label: "B", Draw: func(graph pipeline.Graph) { graph.AddActivity("b") }},
+	)
+
+	content := diagram.String()
+	expectedContent := "\nswitch (choice)\ncase (A)\n:a;\ncase (B)\n:b;\nendswitch\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenAGraph_WhenAddingZeroSwitchCases_ThenNothingHappens(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddSwitch("choice")
+
+	assert.NotContains(t, diagram.String(), "switch")
+}
+
+func TestUMLGraph_GivenAGraph_WhenAddingANote_ThenPlantUMLNoteIsAdded(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddActivity("activity")
+	diagram.AddNote("some note")
+
+	content := diagram.String()
+	expectedContent := "\n:activity;\nnote right\nsome note\nend note\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenAGraph_WhenAddingAnErrorHandler_ThenPlantUMLErrorEdgeIsAdded(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddErrorHandler("errored", func(graph pipeline.Graph) {
+		graph.AddActivity("body")
+	}, func(graph pipeline.Graph) {
+		graph.AddActivity("handler")
+	})
+
+	content := diagram.String()
+	expectedContent := "\n:body;\nif (errored) then (error)\n-[#red,dashed]->\n:handler;\nendif\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenAGraph_WhenAddingAPartition_ThenPlantUMLPartitionIsAdded(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddPartition("group", func(graph pipeline.Graph) {
+		graph.AddActivity("inside")
+	})
+
+	content := diagram.String()
+	expectedContent := "\npartition \"group\" {\n:inside;\n}\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenLabelsWithPlantUMLSyntax_WhenDrawn_ThenTheyAreEscaped(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddDecision("is (this) a test?", func(graph pipeline.Graph) {
+		graph.AddActivity("first; second")
+	}, func(graph pipeline.Graph) {
+		graph.AddActivity("multi\nline")
+	})
+
+	content := diagram.String()
+	expectedContent := "\nif (is <U+0028>this<U+0029> a test?) then (yes)\n:first<U+003B> second;\nelse (no)\n:multi\\nline;\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenOptions_WhenStringRepresentationIsAsked_ThenThemeAndSkinParamsAreInTheHeader(t *testing.T) {
+	diagram := pipeline.NewUMLGraphWithOptions(pipeline.UMLGraphOptions{
+		Theme: "cerulean",
+		SkinParams: map[string]string{
+			"shadowing":               "false",
+			"ActivityBackgroundColor": "#ffffff",
+		},
+	})
+
+	content := diagram.String()
+	expectedContent := "@startuml\n!theme cerulean\nskinparam ActivityBackgroundColor #ffffff\nskinparam shadowing false\nstart\n"
+
+	assert.Equal(t, expectedContent+"stop\n@enduml\n", content)
+}
+
+func TestUMLGraph_GivenAStyledActivity_WhenDrawn_ThenItIsColoredAndStereotyped(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddStyledActivity("activity", pipeline.ActivityStyle{
+		Color:       "palegreen",
+		Stereotypes: []string{"traced", "metrics"},
+	})
+
+	assert.Contains(t, diagram.String(), "\n#palegreen:activity; <<traced, metrics>>\n")
+}
+
+func TestUMLGraph_GivenConfiguredStyles_WhenDrawingActivities_ThenTheyAreMergedByLabel(t *testing.T) {
+	diagram := pipeline.NewUMLGraphWithOptions(pipeline.UMLGraphOptions{
+		Styles: map[string]pipeline.ActivityStyle{
+			"activity": {Color: "#tomato", Stereotypes: []string{"critical"}},
+		},
+	})
+	diagram.AddStyledActivity("activity", pipeline.ActivityStyle{Color: "#palegreen", Stereotypes: []string{"traced"}})
+	diagram.AddActivity("other")
+
+	content := diagram.String()
+	expectedContent := "\n#tomato:activity; <<traced, critical>>\n:other;\n"
+
+	assert.Contains(t, content, expectedContent)
+}
+
+func TestUMLGraph_GivenDecoratedSteps_WhenDrawn_ThenActivitiesAreStereotypedByKind(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	step := pipeline.NewTracedStepWithTracer[int, int](
+		"traced",
+		pipeline.NewMetricsStep[int, int](
+			"metrics",
+			pipeline.NewHaltBoundaryStep[int, int](
+				pipeline.NewUnitStep("unit", func(ctx context.Context, i int) (int, error) { return i, nil }),
+			),
+			pipeline.NewMemoryMetricsCollector(pipeline.MetricsOptions{}),
+		),
+		pipeline.NewRecordingTracer(),
+	)
+
+	step.Draw(diagram)
+
+	assert.Contains(t, diagram.String(), "\n:unit; <<unit, halt_boundary, metrics, traced>>\n")
+}
+
+func TestUMLGraph_GivenADescribedActivity_WhenDrawn_ThenANoteWithTheMetadataIsAdded(t *testing.T) {
+	diagram := pipeline.NewUMLGraph()
+	diagram.AddDescribedActivity("activity", pipeline.ActivityStyle{}, pipeline.StepMetadata{
+		Description: "does (things)",
+		Owner:       "team",
+		Tags:        []string{"a", "b"},
+		Link:        "http://docs/a b",
+	})
+
+	content := diagram.String()
+	expectedContent := "\n:activity;\nnote right\ndoes <U+0028>things<U+0029>\nowner: team\ntags: a, b\n[[http://docs/a%20b]]\nend note\n"
+
+	assert.Contains(t, content, expectedContent)
+	assert.NotContains(t, content, "|team|")
+}
+
+func TestUMLGraph_GivenSwimlanes_WhenDrawingDescribedActivities_ThenTheyAreGroupedByOwner(t *testing.T) {
+	diagram := pipeline.NewUMLGraphWithOptions(pipeline.UMLGraphOptions{Swimlanes: true})
+	unit := func(name, owner string) pipeline.Step[int, int] {
+		return pipeline.NewUnitStep(name, func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		}).WithMetadata(pipeline.StepMetadata{Owner: owner})
+	}
+
+	pipeline.NewSequentialStep(
+		pipeline.NewSequentialStep(unit("first", "a"), unit("second", "a")),
+		pipeline.NewSequentialStep(unit("third", "b"), pipeline.NewUnitStep("fourth", func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		})),
+	).Draw(diagram)
+
+	content := diagram.String()
+	assert.Contains(t, content, "start\n|a|\n:first; <<unit>>\n")
+	assert.Contains(t, content, "end note\n|b|\n:third; <<unit>>\n")
+	assert.Contains(t, content, "end note\n:fourth; <<unit>>\n")
+	assert.Equal(t, 1, strings.Count(content, "|a|"))
+}
@@ -3,16 +3,25 @@ package pipeline
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 const (
 	// Base64 Encoding maps
 	mapper = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+	// Header of the encoded plant UML texts, signaling they are deflated with zlib
+	plantUMLHeader = "~1"
 	// Base URL for plant UML graphs creation
 	baseURL = "http://www.plantuml.com/plantuml"
+	// Maximum length of the URLs used for retrieving graphs, longer ones are POSTed instead
+	maxURLLength = 4096
+	// Time waited before the first retry of a failed request, doubled on each following one
+	retryBackoff = 250 * time.Millisecond
 
 	// UMLFormatPNG OutputFormat for graph renderings (a UMLFormatPNG image will be created)
 	UMLFormatPNG UMLOutputFormat = "png"
@@ -34,18 +43,23 @@ type (
 		Type UMLOutputFormat
 		// Base URL to use for retrieving Plant UML graphs, by default we will use http://www.plantuml.com/plantuml/
 		BaseURL string
+		// Client to use for retrieving Plant UML graphs, by default we will use http.DefaultClient
+		Client *http.Client
+		// Retries is the amount of times a request is retried when the server fails with a 5xx status code,
+		// by default requests aren't retried
+		Retries int
+		// RetryBackoff is the time waited before the first retry, doubled on each following one.
+		// By default we will use 250ms
+		RetryBackoff time.Duration
+		// MaxURLLength is the maximum length of the URL of a GET request. Diagrams whose encoded URL exceeds it
+		// are sent in the body of a POST request instead. By default we will use 4096
+		MaxURLLength int
 	}
 
 	// UMLRenderer allows us to render graphs into an UML diagram output
 	UMLRenderer struct {
 		Options UMLOptions
 	}
-
-	umlRendererGraph interface {
-		Graph
-
-		String() string
-	}
 )
 
 // NewUMLRenderer creates an UML renderer for drawing graphs as specified
@@ -58,52 +72,148 @@ func NewUMLRenderer(options UMLOptions) *UMLRenderer {
 		options.BaseURL = baseURL
 	}
 
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = retryBackoff
+	}
+
+	if options.MaxURLLength <= 0 {
+		options.MaxURLLength = maxURLLength
+	}
+
 	return &UMLRenderer{
 		Options: options,
 	}
 }
 
-// Render draws in UML activity the given step, and writes it to the given file
-func (u *UMLRenderer) Render(graphDiagram umlRendererGraph, output io.WriteCloser) error {
+// Render draws in UML activity the given graph, and writes it to the given output. The output isn't closed
+func (u *UMLRenderer) Render(graphDiagram RenderableGraph, output io.Writer) error {
+	return u.RenderContext(context.Background(), graphDiagram, output)
+}
+
+// RenderContext draws in UML activity the given graph, and writes it to the given output. The output isn't closed.
+// The context bounds the requests made to the Plant UML server (and the waits between its retries)
+func (u *UMLRenderer) RenderContext(ctx context.Context, graphDiagram RenderableGraph, output io.Writer) error {
 	content := graphDiagram.String()
 
 	if u.Options.Type == UMLFormatRaw {
 		_, err := io.WriteString(output, content)
 		return err
 	}
-	return u.renderUml([]byte(content), output)
+	return u.renderUml(ctx, []byte(content), output)
+}
+
+// Render as  UML the contents, writing them into the output
+func (u *UMLRenderer) renderUml(ctx context.Context, content []byte, output io.Writer) error {
+	response, err := u.request(ctx, content)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	_, err = io.Copy(output, response.Body)
+	return err
 }
 
-// Render as  UML the contents, writing them into the File
-func (u *UMLRenderer) renderUml(content []byte, output io.WriteCloser) error {
-	content = u.deflate(content)
-	url := fmt.Sprintf("%s/%s/~1%s", u.Options.BaseURL, u.Options.Type, u.base64Encode(content))
+// request the rendering of the contents, retrying it while the server fails with a 5xx status code.
+// A successful response is returned with its body pending to be read and closed
+func (u *UMLRenderer) request(ctx context.Context, content []byte) (*http.Response, error) {
+	backoff := u.Options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := u.newRequest(ctx, content)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := u.Options.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode == http.StatusOK {
+			return response, nil
+		}
+		_ = response.Body.Close()
+
+		err = fmt.Errorf("status code %d while trying to create the graph through %s", response.StatusCode, req.URL)
+		if response.StatusCode < 500 || attempt >= u.Options.Retries {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
 
-	response, err := http.Get(url)
+// newRequest for rendering the contents. They are encoded in the URL of a GET request, unless it gets too long
+// in which case they are sent as the body of a POST one
+func (u *UMLRenderer) newRequest(ctx context.Context, content []byte) (*http.Request, error) {
+	url := u.url(string(content))
+	if len(url) <= u.Options.MaxURLLength {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	}
 
+	url = fmt.Sprintf("%s/%s", u.Options.BaseURL, u.Options.Type)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(content))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if response.StatusCode != 200 {
-		return fmt.Errorf("status code %d while trying to create the graph through %s", response.StatusCode, url)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	return req, nil
+}
+
+// URL of the Plant UML server rendering the graph, with the graph encoded in it. Useful for linking a diagram
+// without fetching it. For the UMLFormatRaw type, the URL of the server's editor is returned
+func (u *UMLRenderer) URL(graphDiagram RenderableGraph) string {
+	return u.url(graphDiagram.String())
+}
+
+func (u *UMLRenderer) url(content string) string {
+	format := string(u.Options.Type)
+	if u.Options.Type == UMLFormatRaw {
+		format = "uml"
 	}
+	return fmt.Sprintf("%s/%s/%s", u.Options.BaseURL, format, EncodePlantUML(content))
+}
 
-	_, err = io.Copy(output, response.Body)
+// EncodePlantUML compresses and encodes the text as Plant UML servers expect it in their URLs
+// (eg. http://www.plantuml.com/plantuml/svg/<encoded>)
+func EncodePlantUML(text string) string {
+	return plantUMLHeader + base64Encode(deflate([]byte(text)))
+}
 
+// DecodePlantUML decodes and decompresses the text encoded by EncodePlantUML
+func DecodePlantUML(encoded string) (string, error) {
+	content, err := base64Decode(strings.TrimPrefix(encoded, plantUMLHeader))
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return output.Close()
+	r, err := zlib.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("plant uml text can't be inflated: %w", err)
+	}
+	defer r.Close()
+
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("plant uml text can't be inflated: %w", err)
+	}
+	return string(text), nil
 }
 
-// Encode in standard B64 the given input
-func (u *UMLRenderer) base64Encode(input []byte) string {
+// Encode in Plant UML B64 the given input. Incomplete trailing groups are padded with zeroes
+func base64Encode(input []byte) string {
 	var buffer bytes.Buffer
 	inputLength := len(input)
-	for i := 0; i < 3-inputLength%3; i++ {
-		input = append(input, byte(0))
-	}
+	padding := (3 - inputLength%3) % 3
+	input = append(input[:inputLength:inputLength], make([]byte, padding)...)
 
 	for i := 0; i < inputLength; i += 3 {
 		b1, b2, b3 := input[i], input[i+1], input[i+2]
@@ -120,8 +230,34 @@ func (u *UMLRenderer) base64Encode(input []byte) string {
 	return buffer.String()
 }
 
+// Decode from Plant UML B64 the given input. Trailing zeroes of an incomplete group are kept
+func base64Decode(input string) ([]byte, error) {
+	if len(input)%4 != 0 {
+		return nil, fmt.Errorf("plant uml text has an invalid length %d", len(input))
+	}
+
+	output := make([]byte, 0, len(input)/4*3)
+	for i := 0; i < len(input); i += 4 {
+		var group [4]byte
+		for j := range group {
+			idx := strings.IndexByte(mapper, input[i+j])
+			if idx < 0 {
+				return nil, fmt.Errorf("plant uml text has an invalid character %q", input[i+j])
+			}
+			group[j] = byte(idx)
+		}
+
+		output = append(output,
+			group[0]<<2|group[1]>>4,
+			group[1]<<4|group[2]>>2,
+			group[2]<<6|group[3],
+		)
+	}
+	return output, nil
+}
+
 // Deflate compression algorithm
-func (u *UMLRenderer) deflate(content []byte) []byte {
+func deflate(content []byte) []byte {
 	var b bytes.Buffer
 	w, _ := zlib.NewWriterLevel(&b, zlib.BestCompression)
 	_, _ = w.Write(content)
@@ -1,13 +1,17 @@
 package pipeline_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -15,6 +19,12 @@ import (
 	"github.com/saantiaguilera/go-pipeline"
 )
 
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 type mockWriteCloser struct {
 	mock.Mock
 }
@@ -71,7 +81,6 @@ func TestRenderer_GivenARenderer_WhenRenderingWithoutSpecifyingType_ThenSvgIsUse
 	mockGraph.On("String").Return("content string")
 
 	mockWriteCloser := new(mockWriteCloser)
-	mockWriteCloser.On("Close").Return(nil)
 
 	var urlUsed string
 
@@ -94,7 +103,6 @@ func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenContentsAreDeflat
 	mockGraph.On("String").Return("content string")
 
 	mockWriteCloser := new(mockWriteCloser)
-	mockWriteCloser.On("Close").Return(nil)
 
 	var urlUsed string
 
@@ -110,7 +118,10 @@ func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenContentsAreDeflat
 	err := renderer.Render(mockGraph, mockWriteCloser)
 
 	assert.Nil(t, err)
-	assert.Equal(t, "/svg/~1UDfApiyhISqhKIWkAShCImS4003__ohB1RC0", urlUsed)
+	assert.True(t, strings.HasPrefix(urlUsed, "/svg/~1"))
+	content, err := pipeline.DecodePlantUML(strings.TrimPrefix(urlUsed, "/svg/"))
+	assert.Nil(t, err)
+	assert.Equal(t, "content string", content)
 }
 
 func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenContentsAreSentToPlantUMLServerAndResponseCopiedIntoFile(t *testing.T) {
@@ -119,7 +130,6 @@ func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenContentsAreSentTo
 
 	mockWriteCloser := new(mockWriteCloser)
 	mockWriteCloser.On("Write", []byte("content string")).Return(len("content string"), nil)
-	mockWriteCloser.On("Close").Return(nil)
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte("content string"))
@@ -206,15 +216,12 @@ func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenHandlesIoError(t
 	mockWriteCloser.AssertExpectations(t)
 }
 
-func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenHandlesWriterClose(t *testing.T) {
-	expectedErr := errors.New("error")
-
+func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenOutputIsNotClosed(t *testing.T) {
 	mockGraph := new(mockGraph)
 	mockGraph.On("String").Return("content string")
 
 	mockWriteCloser := new(mockWriteCloser)
 	mockWriteCloser.On("Write", []byte("content string")).Return(len("content string"), nil)
-	mockWriteCloser.On("Close").Return(expectedErr)
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte("content string"))
@@ -227,7 +234,205 @@ func TestRenderer_GivenARenderer_WhenRenderingOtherThanRaw_ThenHandlesWriterClos
 	})
 	err := renderer.Render(mockGraph, mockWriteCloser)
 
-	assert.Equal(t, expectedErr, err)
+	assert.Nil(t, err)
 	mockGraph.AssertExpectations(t)
 	mockWriteCloser.AssertExpectations(t)
+	mockWriteCloser.AssertNotCalled(t, "Close")
+}
+
+func TestRenderer_GivenACanceledContext_WhenRenderingContext_ThenContextErrorIsReturned(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	mockWriteCloser := new(mockWriteCloser)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("content string"))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		BaseURL: ts.URL,
+	})
+	err := renderer.RenderContext(ctx, mockGraph, mockWriteCloser)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	mockWriteCloser.AssertExpectations(t)
+}
+
+func TestRenderer_GivenAServerFailingWith5xx_WhenRendering_ThenRequestIsRetried(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	mockWriteCloser := new(mockWriteCloser)
+	mockWriteCloser.On("Write", []byte("content string")).Return(len("content string"), nil)
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("content string"))
+	}))
+	defer ts.Close()
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		BaseURL:      ts.URL,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+	})
+	err := renderer.Render(mockGraph, mockWriteCloser)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	mockWriteCloser.AssertExpectations(t)
+}
+
+func TestRenderer_GivenAServerFailingWith5xx_WhenRetriesAreExhausted_ThenErrorIsReturned(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	mockWriteCloser := new(mockWriteCloser)
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		BaseURL:      ts.URL,
+		Retries:      1,
+		RetryBackoff: time.Millisecond,
+	})
+	err := renderer.Render(mockGraph, mockWriteCloser)
+
+	assert.ErrorContains(t, err, "status code 502")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	mockWriteCloser.AssertExpectations(t)
+}
+
+func TestRenderer_GivenALargeDiagram_WhenRendering_ThenContentsArePosted(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	mockWriteCloser := new(mockWriteCloser)
+	mockWriteCloser.On("Write", []byte("rendered")).Return(len("rendered"), nil)
+
+	var method, path, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		_, _ = w.Write([]byte("rendered"))
+	}))
+	defer ts.Close()
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		Type:         pipeline.UMLFormatPNG,
+		BaseURL:      ts.URL,
+		MaxURLLength: 10,
+	})
+	err := renderer.Render(mockGraph, mockWriteCloser)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/png", path)
+	assert.Equal(t, "content string", body)
+	mockWriteCloser.AssertExpectations(t)
+}
+
+func TestRenderer_GivenAClient_WhenRendering_ThenItIsUsed(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	mockWriteCloser := new(mockWriteCloser)
+	mockWriteCloser.On("Write", []byte("content string")).Return(len("content string"), nil)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Client")))
+	}))
+	defer ts.Close()
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		BaseURL: ts.URL,
+		Client: &http.Client{
+			Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				r.Header.Set("X-Client", "content string")
+				return http.DefaultTransport.RoundTrip(r)
+			}),
+		},
+	})
+	err := renderer.Render(mockGraph, mockWriteCloser)
+
+	assert.Nil(t, err)
+	mockWriteCloser.AssertExpectations(t)
+}
+
+func TestRenderer_GivenARenderer_WhenAskingTheURL_ThenItContainsTheEncodedGraph(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		Type:    pipeline.UMLFormatPNG,
+		BaseURL: "http://plantuml.local",
+	})
+
+	assert.Equal(t, "http://plantuml.local/png/"+pipeline.EncodePlantUML("content string"), renderer.URL(mockGraph))
+}
+
+func TestRenderer_GivenARawRenderer_WhenAskingTheURL_ThenTheEditorURLIsReturned(t *testing.T) {
+	mockGraph := new(mockGraph)
+	mockGraph.On("String").Return("content string")
+
+	renderer := pipeline.NewUMLRenderer(pipeline.UMLOptions{
+		Type: pipeline.UMLFormatRaw,
+	})
+
+	assert.Equal(t, "http://www.plantuml.com/plantuml/uml/"+pipeline.EncodePlantUML("content string"), renderer.URL(mockGraph))
+}
+
+func TestEncodePlantUML_GivenAText_ThenItIsEncodedInCompleteGroupsOfThePlantUMLAlphabet(t *testing.T) {
+	encoded := pipeline.EncodePlantUML("@startuml\nstop\n@enduml")
+
+	assert.True(t, strings.HasPrefix(encoded, "~1"))
+	assert.Regexp(t, "^~1[0-9A-Za-z_-]+$", encoded)
+	assert.Zero(t, (len(encoded)-2)%4)
+}
+
+func TestDecodePlantUML_GivenAnInvalidCharacter_ThenErrorIsReturned(t *testing.T) {
+	_, err := pipeline.DecodePlantUML("~1UDf+")
+
+	assert.NotNil(t, err)
+}
+
+func TestDecodePlantUML_GivenAnInvalidLength_ThenErrorIsReturned(t *testing.T) {
+	_, err := pipeline.DecodePlantUML("~1UDf")
+
+	assert.NotNil(t, err)
+}
+
+func TestDecodePlantUML_GivenNonDeflatedContent_ThenErrorIsReturned(t *testing.T) {
+	_, err := pipeline.DecodePlantUML("~1AAAA")
+
+	assert.NotNil(t, err)
+}
+
+func FuzzEncodePlantUML_GivenAText_WhenEncodedAndDecoded_ThenItIsTheSame(f *testing.F) {
+	for _, seed := range []string{"", "a", "ab", "abc", "abcd", "abcdef", "content string", "@startuml\n:ñandú;\n@enduml"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		encoded := pipeline.EncodePlantUML(text)
+
+		decoded, err := pipeline.DecodePlantUML(encoded)
+
+		assert.Nil(t, err)
+		assert.Equal(t, text, decoded)
+	})
 }
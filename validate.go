@@ -0,0 +1,180 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// ValidationError is the error returned by Validate, with every problem found in a step tree
+	ValidationError struct {
+		Problems []ValidationProblem
+	}
+
+	// ValidationProblem is a structural problem of a step, found by Validate
+	ValidationProblem struct {
+		// Path of the step with the problem (see Walk)
+		Path []string
+		// Message describing the problem
+		Message string
+	}
+
+	// validatableStep is implemented by the built-in steps for reporting their own structural problems
+	validatableStep interface {
+		problems() []string
+	}
+
+	validation struct {
+		problems []ValidationProblem
+		// units by name, with the ID and path of the first one found
+		units map[string]validatedUnit
+		// paths of the checkpoint steps found
+		checkpoints map[string]bool
+	}
+
+	validatedUnit struct {
+		id   string
+		path []string
+	}
+)
+
+// Validate the step tree, returning a ValidationError with every structural problem found in it. Eg.
+//   - nil steps, branches or statements (and statements without evaluation)
+//   - empty concurrent steps
+//   - different units sharing a name, as RunRecorder, metrics and traces identify units by name (so their
+//     recordings, series and spans would be merged)
+//   - checkpoint steps sharing a path (as their checkpoints are keyed by it, see CheckpointStep), which only custom
+//     steps lead to, as the children of steps that aren't built-in aren't told apart
+//   - branches that can't be reached because the conditional or optional step directly enclosing them already
+//     decided on the same statement (steps in between may change the input, so only direct nesting is checked)
+//
+// Validate is meant to be called at startup, so misconfigured pipelines fail before running.
+// Custom steps are validated through their children if they implement InspectableStep.
+func Validate(step DrawableGraph) error {
+	if step == nil {
+		return &ValidationError{Problems: []ValidationProblem{{Message: "nil step"}}}
+	}
+
+	v := validation{
+		units:       make(map[string]validatedUnit),
+		checkpoints: make(map[string]bool),
+	}
+	v.validate(step, []string{stepSegment(step)}, map[string]bool{})
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("invalid pipeline, %d problems found:\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+func (p ValidationProblem) String() string {
+	if len(p.Path) == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(p.Path, "/"), p.Message)
+}
+
+// validate the step (found at the given path) and its children. Decided are the statements the directly enclosing
+// conditional and optional steps evaluated for reaching it, along with their results. As they run with the same
+// input, evaluating them again yields the same results
+func (v *validation) validate(step DrawableGraph, path []string, decided map[string]bool) {
+	if s, ok := step.(validatableStep); ok {
+		for _, p := range s.problems() {
+			v.report(path, p)
+		}
+	}
+
+	kind := KindOf(step)
+	switch kind {
+	case StepKindUnit:
+		v.validateName(step, path)
+	case StepKindCheckpoint:
+		v.validateCheckpoint(path)
+	}
+
+	var statement string
+	if kind == StepKindConditional || kind == StepKindOptional {
+		if n, ok := step.(interface{ Name() string }); ok {
+			statement = n.Name()
+		}
+	}
+	result, isDecided := decided[statement]
+	isDecided = isDecided && len(statement) > 0
+
+	for i, c := range ChildrenOf(step) {
+		if c == nil {
+			continue
+		}
+
+		var childDecided map[string]bool // any other step may change the input, so nothing is decided for its children
+		if len(statement) > 0 {
+			branch := kind == StepKindOptional || i == 0
+			if isDecided && branch != result {
+				v.report(path, fmt.Sprintf("%s branch is unreachable, statement %q already held %t", branchName(kind, branch), statement, result))
+				continue
+			}
+			childDecided = make(map[string]bool, len(decided)+1)
+			for k, r := range decided {
+				childDecided[k] = r
+			}
+			childDecided[statement] = branch
+		}
+
+		v.validate(c, childPath(step, path, i, c), childDecided)
+	}
+}
+
+// validateName of the unit, reporting it if another unit has it already
+func (v *validation) validateName(step DrawableGraph, path []string) {
+	name := stepSegment(step)
+	if len(name) == 0 {
+		return
+	}
+
+	var id string
+	if s, ok := step.(interface{ ID() string }); ok {
+		id = s.ID()
+	}
+
+	first, ok := v.units[name]
+	if !ok {
+		v.units[name] = validatedUnit{id: id, path: path}
+		return
+	}
+	if first.id != id {
+		v.report(path, fmt.Sprintf(
+			"duplicate name %q, also used by %s (units are recorded by name, see RunRecorder)",
+			name,
+			strings.Join(first.path, "/"),
+		))
+	}
+}
+
+// validateCheckpoint path, reporting it if another checkpoint step has it already
+func (v *validation) validateCheckpoint(path []string) {
+	key := strings.Join(path, "/")
+	if v.checkpoints[key] {
+		v.report(path, "duplicate checkpoint path, also used by another checkpoint step")
+		return
+	}
+	v.checkpoints[key] = true
+}
+
+func (v *validation) report(path []string, message string) {
+	v.problems = append(v.problems, ValidationProblem{Path: path, Message: message})
+}
+
+func branchName(kind StepKind, branch bool) string {
+	if kind == StepKindOptional {
+		return "optional"
+	}
+	return fmt.Sprint(branch)
+}
@@ -0,0 +1,128 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saantiaguilera/go-pipeline"
+)
+
+func validationMessages(t *testing.T, err error) []string {
+	var verr *pipeline.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	res := make([]string, len(verr.Problems))
+	for i, p := range verr.Problems {
+		res[i] = p.String()
+	}
+	return res
+}
+
+func TestValidate_GivenAValidPipeline_ThenNoErrorIsReturned(t *testing.T) {
+	unit := newIntrospectedUnit("unit")
+	isEven := pipeline.NewStatement("is_even", func(ctx context.Context, i int) bool {
+		return i%2 == 0
+	})
+
+	err := pipeline.Validate(pipeline.NewSequentialStep[int, int, int](
+		unit,
+		pipeline.NewSequentialStep[int, int, int](
+			pipeline.NewConditionalStep(isEven, newIntrospectedUnit("even"), newIntrospectedUnit("odd")),
+			unit, // the same unit can be reused
+		),
+	))
+
+	assert.Nil(t, err)
+}
+
+func TestValidate_GivenANilStep_ThenItIsReported(t *testing.T) {
+	assert.Equal(t, []string{"nil step"}, validationMessages(t, pipeline.Validate(nil)))
+}
+
+func TestValidate_GivenAMisconfiguredPipeline_ThenEveryProblemIsReportedWithItsPath(t *testing.T) {
+	sum := func(ctx context.Context, a, b int) (int, error) {
+		return a + b, nil
+	}
+
+	err := pipeline.Validate(pipeline.NewSequentialStep[int, int, int](
+		pipeline.NewSequentialStep[int, int, int](nil, pipeline.NewConcurrentStep[int, int](nil, sum)),
+		pipeline.NewSequentialStep[int, int, int](
+			pipeline.NewConcurrentStep([]pipeline.Step[int, int]{newIntrospectedUnit("a"), nil}, nil),
+			pipeline.NewSequentialStep[int, int, int](
+				pipeline.NewConditionalStep(pipeline.NewAnonymousStatement[int](nil), newIntrospectedUnit("b"), nil),
+				pipeline.NewUnitStep[int, int]("c", nil),
+			),
+		),
+	))
+
+	assert.Equal(t, []string{
+		"sequential/0/sequential: nil start step",
+		"sequential/0/sequential/1/concurrent: empty concurrent steps",
+		"sequential/1/sequential/0/concurrent: nil step at index 1",
+		"sequential/1/sequential/0/concurrent: nil reducer",
+		"sequential/1/sequential/1/sequential/0/conditional: statement \"\" without evaluation, it always evaluates to false",
+		"sequential/1/sequential/1/sequential/0/conditional: nil false branch",
+		"sequential/1/sequential/1/sequential/1/c: nil unit function",
+	}, validationMessages(t, err))
+	assert.Contains(t, err.Error(), "invalid pipeline, 7 problems found:\nsequential/0/sequential: nil start step\n")
+}
+
+func TestValidate_GivenDifferentUnitsWithTheSameName_ThenTheyAreReported(t *testing.T) {
+	err := pipeline.Validate(pipeline.NewSequentialStep[int, int, int](
+		newIntrospectedUnit("unit"),
+		pipeline.NewTracedStepWithTracer[int, int]("trace", newIntrospectedUnit("unit"), pipeline.NewRecordingTracer()),
+	))
+
+	assert.Equal(t, []string{
+		"sequential/1/traced:trace/unit: duplicate name \"unit\", also used by sequential/0/unit (units are recorded by name, see RunRecorder)",
+	}, validationMessages(t, err))
+}
+
+func TestValidate_GivenBranchesDecidedByAnEnclosingStatement_ThenUnreachableOnesAreReported(t *testing.T) {
+	isEven := pipeline.NewStatement("is_even", func(ctx context.Context, i int) bool {
+		return i%2 == 0
+	})
+
+	err := pipeline.Validate(pipeline.NewConditionalStep(
+		isEven,
+		pipeline.NewConditionalStep(isEven, newIntrospectedUnit("a"), newIntrospectedUnit("b")),
+		pipeline.NewOptionalStep[int](isEven, newIntrospectedUnit("c")),
+	))
+
+	assert.Equal(t, []string{
+		"conditional:is_even/true/conditional:is_even: false branch is unreachable, statement \"is_even\" already held true",
+		"conditional:is_even/false/optional:is_even: optional branch is unreachable, statement \"is_even\" already held false",
+	}, validationMessages(t, err))
+}
+
+func TestValidate_GivenAStatementDecidedBeforeChangingTheInput_ThenItsBranchesAreReachable(t *testing.T) {
+	isEven := pipeline.NewStatement("is_even", func(ctx context.Context, i int) bool {
+		return i%2 == 0
+	})
+	inc := pipeline.NewUnitStep("inc", func(ctx context.Context, i int) (int, error) {
+		return i + 1, nil
+	})
+	step := pipeline.NewConditionalStep[int, int](
+		isEven,
+		pipeline.NewSequentialStep[int, int, int](inc, pipeline.NewConditionalStep[int, int](isEven, newIntrospectedUnit("a"), newIntrospectedUnit("b"))),
+		newIntrospectedUnit("c"),
+	)
+
+	err := pipeline.Validate(step)
+	res, runErr := step.Run(context.Background(), 2)
+
+	assert.Nil(t, err)
+	assert.Nil(t, runErr)
+	assert.Equal(t, 3, res)
+}
+
+func TestValidate_GivenANilStatement_ThenItIsReported(t *testing.T) {
+	err := pipeline.Validate(pipeline.NewConditionalStep[int, int](nil, newIntrospectedUnit("a"), newIntrospectedUnit("b")))
+
+	assert.Equal(t, []string{"conditional: nil statement"}, validationMessages(t, err))
+}